@@ -8,7 +8,8 @@ govendor init
 
 govendor fetch gopkg.in/yaml.v2
 govendor fetch github.com/maxmanuylov/go-rest/client
-govendor fetch github.com/maxmanuylov/utils/http/transport/tls
+govendor fetch golang.org/x/crypto/ssh/knownhosts@b176d7def5d71bdd214203491f89843ed217f420
+govendor fetch golang.org/x/crypto/scrypt@b176d7def5d71bdd214203491f89843ed217f420
 govendor fetch github.com/hashicorp/go-plugin@f72692aebca2008343a9deb06ddb4b17f7051c15
 govendor fetch github.com/hashicorp/terraform@=$TERRAFORM_VERSION
 govendor fetch github.com/maxmanuylov/utils/intellij-hcl/terraform/provider-schema-generator@=v2.2
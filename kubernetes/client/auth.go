@@ -0,0 +1,155 @@
+package kubernetes_client
+
+import (
+    "crypto/tls"
+    "fmt"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+)
+
+const tokenFileRefreshInterval = time.Minute
+
+type credentials interface {
+    authorize(request *http.Request) error
+}
+
+type refreshableCredentials interface {
+    invalidate()
+}
+
+type clientCertificateProvider interface {
+    clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+}
+
+func newCredentials(cluster *kubernetes_cluster.Cluster) credentials {
+    switch {
+    case cluster.Eks != nil:
+        return newEksCredentials(cluster.Eks)
+    case cluster.Exec != nil:
+        return newExecCredentials(cluster.Exec)
+    case cluster.TokenFile != "":
+        return &tokenFileCredentials{path: cluster.TokenFile}
+    case cluster.Token != "":
+        return &tokenCredentials{token: cluster.Token}
+    case cluster.Username != "":
+        return &basicCredentials{username: cluster.Username, password: cluster.Password}
+    }
+    return nil
+}
+
+type authTransport struct {
+    transport   http.RoundTripper
+    credentials credentials
+}
+
+func (t *authTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+    request = request.WithContext(request.Context()) // shallow copy, the original request must not be modified
+    request.Header = cloneHeader(request.Header)
+
+    if err := t.credentials.authorize(request); err != nil {
+        return nil, err
+    }
+
+    response, err := t.transport.RoundTrip(request)
+
+    if err == nil && response.StatusCode == http.StatusUnauthorized {
+        if refreshable, ok := t.credentials.(refreshableCredentials); ok { // the credential is revoked or expired earlier than declared
+            refreshable.invalidate()
+        }
+    }
+
+    return response, err
+}
+
+type impersonationTransport struct {
+    transport     http.RoundTripper
+    impersonation *kubernetes_cluster.Impersonation
+}
+
+func (t *impersonationTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+    request = request.WithContext(request.Context())
+    request.Header = cloneHeader(request.Header)
+
+    if t.impersonation.User != "" {
+        request.Header.Set("Impersonate-User", t.impersonation.User)
+    }
+
+    for _, group := range t.impersonation.Groups {
+        request.Header.Add("Impersonate-Group", group)
+    }
+
+    for key, values := range t.impersonation.Extra {
+        header := fmt.Sprintf("Impersonate-Extra-%s", url.PathEscape(key)) // keys are case-insensitive and percent-encoded
+        for _, value := range values {
+            request.Header.Add(header, value)
+        }
+    }
+
+    return t.transport.RoundTrip(request)
+}
+
+type tokenCredentials struct {
+    token string
+}
+
+func (c *tokenCredentials) authorize(request *http.Request) error {
+    setBearerToken(request, c.token)
+    return nil
+}
+
+type tokenFileCredentials struct {
+    sync.Mutex
+    path   string
+    token  string
+    readAt time.Time
+}
+
+func (c *tokenFileCredentials) authorize(request *http.Request) error {
+    c.Lock()
+    defer c.Unlock()
+
+    if c.token == "" || time.Since(c.readAt) > tokenFileRefreshInterval { // projected service account tokens are rotated in place
+        contents, err := ioutil.ReadFile(c.path)
+        if err != nil {
+            return fmt.Errorf("Failed to read token file: %v", err)
+        }
+        c.token, c.readAt = strings.TrimSpace(string(contents)), time.Now()
+    }
+
+    setBearerToken(request, c.token)
+    return nil
+}
+
+func (c *tokenFileCredentials) invalidate() {
+    c.Lock()
+    defer c.Unlock()
+
+    c.token = ""
+}
+
+type basicCredentials struct {
+    username string
+    password string
+}
+
+func (c *basicCredentials) authorize(request *http.Request) error {
+    request.SetBasicAuth(c.username, c.password)
+    return nil
+}
+
+func setBearerToken(request *http.Request, token string) {
+    request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+}
+
+func cloneHeader(header http.Header) http.Header {
+    clone := make(http.Header, len(header))
+    for key, values := range header {
+        clone[key] = append([]string(nil), values...)
+    }
+    return clone
+}
@@ -1,12 +1,15 @@
 package kubernetes_client
 
 import (
+    "errors"
     "fmt"
     "github.com/maxmanuylov/go-rest/client"
     "github.com/maxmanuylov/go-rest/error"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
+    "log"
     "net/http"
+    "net/url"
     "strings"
     "time"
 )
@@ -17,27 +20,75 @@ var (
 )
 
 type KubeClient struct {
-    restClient *rest_client.Client
+    apiServer        string
+    namespace        string
+    deadline         time.Time
+    retryMaxInterval time.Duration
+    reauthorize      bool // the credentials may be refreshed after "401 Unauthorized"
+    protection       *kubernetes_model.ProtectedObjects
+    throttling       *throttlingTransport
+    httpClient       *http.Client
+    restClient       *rest_client.Client
 }
 
-func New(cluster *kubernetes_cluster.Cluster) (*KubeClient, error) {
-    transport, err := newTransport(cluster)
+func New(cluster *kubernetes_cluster.Cluster, timeout time.Duration) (*KubeClient, error) {
+    if cluster.ApiServer == "" {
+        return nil, errors.New("Kubernetes API server is not specified: set \"api_server\" or \"config_path\", or run inside of a pod")
+    }
+
+    if err := cluster.CheckCertificates(); err != nil {
+        return nil, err
+    }
+
+    credentials := newCredentials(cluster)
+    _, refreshable := credentials.(refreshableCredentials)
+
+    transport, err := newTransport(cluster, credentials)
     if err != nil {
         return nil, err
     }
 
+    retryMaxInterval, ok := cluster.RetryMaxIntervalDuration()
+    if !ok {
+        retryMaxInterval = DefaultRetryMaxInterval
+    }
+
+    requestTimeout, ok := cluster.RequestTimeoutDuration()
+    if !ok {
+        requestTimeout = DefaultRequestTimeout
+    }
+
+    apiServer := strings.TrimSuffix(cluster.ApiServer, "/")
+    throttling := &throttlingTransport{
+        transport: transport,
+    }
+    httpClient := &http.Client{
+        Transport: throttling,
+        Timeout:   requestTimeout,
+    }
+
+    namespace := cluster.Namespace
+    if namespace == "" {
+        namespace = kubernetes_model.DefaultNamespace
+    }
+
     return &KubeClient{
-        restClient: rest_client.New(strings.TrimSuffix(cluster.ApiServer, "/"), &http.Client{
-            Transport: transport,
-            Timeout:   10 * time.Second,
-        }),
+        apiServer:        apiServer,
+        namespace:        namespace,
+        deadline:         time.Now().Add(timeout),
+        retryMaxInterval: retryMaxInterval,
+        reauthorize:      refreshable,
+        protection:       cluster.ProtectedObjects(),
+        throttling:       throttling,
+        httpClient:       httpClient,
+        restClient:       rest_client.New(apiServer, httpClient),
     }, nil
 }
 
 func (client *KubeClient) WaitForAPIServer() error {
     action := "connect to Kubernetes API server"
 
-    eh := retryLong(action, nil, func() error {
+    eh := client.retry(action, nil, func() error {
         _, err := client.restClient.Do("GET", kubernetes_model.DefaultApiPath, rest_client.Json, nil)
         return err
     })
@@ -50,11 +101,38 @@ func (client *KubeClient) WaitForAPIServer() error {
 }
 
 func (client *KubeClient) Create(resource *kubernetes_model.KubeResource) error {
+    if err := client.create(resource); err != nil {
+        return err
+    }
+    return client.waitForReady(resource)
+}
+
+func (client *KubeClient) Update(resource *kubernetes_model.KubeResource) error {
+    if err := client.update(resource); err != nil {
+        return err
+    }
+    return client.waitForReady(resource)
+}
+
+func (client *KubeClient) create(resource *kubernetes_model.KubeResource) error {
+    if resource.IsServerSideApply() {
+        return client.apply(resource)
+    }
+
     collection := client.restClient.Collection(resource.CollectionPath())
     action := fmt.Sprintf("create %s", resource.Path())
 
-    eh := retryLong(action, resource.Contents, func() error {
-        err := createResource(collection, resource.Encoding, resource.Contents)
+    encoding, contents := resource.Encoding, resource.Contents
+    if resource.IsThreeWayMerge() {
+        var err error
+        if contents, err = kubernetes_model.WithLastAppliedConfig(resource.Contents, resource.Encoding); err != nil {
+            return fmt.Errorf("Failed to %s: %v", action, err)
+        }
+        encoding = kubernetes_model.EncodingJson
+    }
+
+    eh := client.retry(action, resource.Contents, func() error {
+        err := createResource(collection, encoding, contents)
         if err == http.ErrNoLocation {
             return nil
         }
@@ -62,7 +140,7 @@ func (client *KubeClient) Create(resource *kubernetes_model.KubeResource) error
     })
 
     if eh.error == ErrConflict { // resource already exists
-        return client.Update(resource)
+        return client.update(resource)
     }
 
     if eh.error != nil {
@@ -73,11 +151,28 @@ func (client *KubeClient) Create(resource *kubernetes_model.KubeResource) error
     return nil
 }
 
-func (client *KubeClient) Update(resource *kubernetes_model.KubeResource) error {
+func (client *KubeClient) update(resource *kubernetes_model.KubeResource) error {
+    if resource.IsServerSideApply() {
+        return client.apply(resource)
+    }
+
+    if resource.IsThreeWayMerge() {
+        return client.threeWayMerge(resource)
+    }
+
     collection := client.restClient.Collection(resource.CollectionPath())
     action := fmt.Sprintf("update %s", resource.Path())
 
-    eh := retryLong(action, resource.Contents, func() error {
+    patchContentType, patch, err := updatePatch(resource)
+    if err != nil {
+        return fmt.Errorf("Failed to %s: %v", action, err)
+    }
+
+    eh := client.retry(action, resource.Contents, func() error {
+        if patch != nil {
+            _, err := client.request("PATCH", resource.Path(), nil, patchContentType, patch)
+            return err
+        }
         return updateResource(collection, resource.Name, resource.Encoding, resource.Contents)
     })
 
@@ -88,12 +183,104 @@ func (client *KubeClient) Update(resource *kubernetes_model.KubeResource) error
     return eh.error
 }
 
+func (client *KubeClient) threeWayMerge(resource *kubernetes_model.KubeResource) error {
+    action := fmt.Sprintf("update %s", resource.Path())
+
+    desiredContents, err := kubernetes_model.WithLastAppliedConfig(resource.Contents, resource.Encoding)
+    if err != nil {
+        return fmt.Errorf("Failed to %s: %v", action, err)
+    }
+
+    eh := client.retry(action, resource.Contents, func() error {
+        liveContents, err := client.request("GET", resource.Path(), nil, "", nil)
+        if err != nil {
+            return err
+        }
+
+        patch, strategic, err := kubernetes_model.ThreeWayMergePatch(desiredContents, liveContents)
+        if err != nil || patch == nil {
+            return err
+        }
+
+        contentType := contentTypeMergePatch
+        if strategic {
+            contentType = contentTypeStrategicMergePatch
+        }
+
+        _, err = client.request("PATCH", resource.Path(), nil, contentType, patch)
+        return err
+    })
+
+    if eh.error != nil {
+        dumpErrorsToFile(action, resource.Contents, eh)
+    }
+
+    return eh.error
+}
+
+func (client *KubeClient) apply(resource *kubernetes_model.KubeResource) error {
+    action := fmt.Sprintf("apply %s", resource.Path())
+
+    query := url.Values{}
+    query.Set("fieldManager", resource.FieldManager)
+    if resource.ForceConflicts {
+        query.Set("force", "true")
+    }
+
+    eh := client.retry(action, resource.Contents, func() error {
+        _, err := client.request("PATCH", resource.Path(), query, contentTypeApplyPatch, resource.Contents)
+        return err
+    })
+
+    if statusErr, ok := eh.error.(*StatusError); ok && statusErr.Code == http.StatusConflict {
+        return errApplyConflict(action, statusErr)
+    }
+
+    if eh.error != nil {
+        dumpErrorsToFile(action, resource.Contents, eh)
+    }
+
+    return eh.error
+}
+
+func (client *KubeClient) waitForReady(resource *kubernetes_model.KubeResource) error {
+    if !resource.WaitForReady {
+        return nil
+    }
+
+    action := fmt.Sprintf("wait for %s to become ready", resource.Path())
+
+    eh := client.retry(action, nil, func() error {
+        liveContents, err := client.request("GET", resource.Path(), nil, "", nil)
+        if err != nil {
+            return err
+        }
+
+        status, err := kubernetes_model.CheckReadiness(liveContents)
+        if err != nil {
+            return &permanentError{err}
+        }
+
+        if status != "" {
+            return errNotReady(status)
+        }
+
+        return nil
+    })
+
+    if eh.error != nil {
+        dumpErrorsToFile(action, nil, eh)
+    }
+
+    return eh.error
+}
+
 func (client *KubeClient) Exists(resourcePath *kubernetes_model.KubeResourcePath) (bool, error) {
     collection := client.restClient.Collection(resourcePath.CollectionPath())
     action := fmt.Sprintf("check existence of %s", resourcePath.Path())
 
     exists := false
-    eh := retryShort(action, nil, func() error {
+    eh := client.retry(action, nil, func() error {
         var err error
         exists, err = collection.Exists(resourcePath.Name)
         return err
@@ -102,16 +289,113 @@ func (client *KubeClient) Exists(resourcePath *kubernetes_model.KubeResourcePath
     return exists, eh.error
 }
 
-func (client *KubeClient) Delete(resourcePath *kubernetes_model.KubeResourcePath) error {
-    if resourcePath.CannotBeDeleted() {
+func (client *KubeClient) Get(resourcePath *kubernetes_model.KubeResourcePath) ([]byte, error) {
+    action := fmt.Sprintf("get %s", resourcePath.Path())
+
+    var contents []byte
+    eh := client.retry(action, nil, func() error {
+        var err error
+        contents, err = client.restClient.Do("GET", resourcePath.Path(), rest_client.Json, nil)
+        return err
+    })
+
+    return contents, eh.error
+}
+
+func (client *KubeClient) Delete(resourcePath *kubernetes_model.KubeResourcePath, options *kubernetes_model.KubeDeleteOptions) error {
+    outcome, err := client.DestroyOutcome(resourcePath, options, nil)
+    if err != nil {
+        return err
+    }
+
+    switch outcome.Action {
+    case kubernetes_model.DestroyAbandon:
+        log.Printf("[WARN] %s is removed from state, but left in the cluster: %s", resourcePath.Path(), outcome.Reason)
         return nil
+    case kubernetes_model.DestroyRefuse:
+        return fmt.Errorf("Refusing to delete %s: %s", resourcePath.Path(), outcome.Reason)
     }
 
-    collection := client.restClient.Collection(resourcePath.CollectionPath())
     action := fmt.Sprintf("delete %s", resourcePath.Path())
 
-    eh := retryShort(action, nil, func() error {
-        return collection.Delete(resourcePath.Name)
+    body, err := options.Body()
+    if err != nil {
+        return fmt.Errorf("Failed to %s: %v", action, err)
+    }
+
+    eh := client.retry(action, nil, func() error {
+        _, err := client.request("DELETE", resourcePath.Path(), nil, contentTypeJson, body)
+        return err
+    })
+
+    if eh.error == ErrNotFound {
+        return nil
+    }
+
+    if eh.error != nil {
+        dumpErrorsToFile(action, nil, eh)
+        return eh.error
+    }
+
+    return client.waitForDeletion(resourcePath, options)
+}
+
+func (client *KubeClient) forceFinalize(resourcePath *kubernetes_model.KubeResourcePath, liveContents []byte) error {
+    _, err := client.request("PATCH", resourcePath.Path(), nil, contentTypeMergePatch, []byte(`{"metadata":{"finalizers":null}}`))
+
+    if err == nil && resourcePath.IsNamespace() {
+        var namespace []byte
+        if namespace, err = kubernetes_model.WithoutSpecFinalizers(liveContents); err != nil {
+            return &permanentError{err}
+        }
+        _, err = client.request("PUT", fmt.Sprintf("%s/finalize", resourcePath.Path()), nil, contentTypeJson, namespace)
+    }
+
+    if statusErr, ok := err.(*StatusError); ok && statusErr.Code != http.StatusNotFound {
+        return fmt.Errorf("Failed to remove finalizers: %v", err) // e.g. conflict with a concurrent update, must be retried
+    }
+
+    return err
+}
+
+// DestroyOutcome tells whether Delete deletes the object, leaves it in the cluster or refuses to delete it,
+// the live object is fetched if it is not given and the protection depends on it
+func (client *KubeClient) DestroyOutcome(resourcePath *kubernetes_model.KubeResourcePath, options *kubernetes_model.KubeDeleteOptions, liveContents []byte) (*kubernetes_model.DestroyOutcome, error) {
+    if liveContents == nil && !options.Abandon && !options.PreventDestroy && client.protection.NeedsLiveObject() {
+        var err error
+        if liveContents, err = client.Get(resourcePath); err == ErrNotFound {
+            liveContents = nil
+        } else if err != nil {
+            return nil, err
+        }
+    }
+
+    return options.Outcome(resourcePath, client.protection, liveContents)
+}
+
+func (client *KubeClient) waitForDeletion(resourcePath *kubernetes_model.KubeResourcePath, options *kubernetes_model.KubeDeleteOptions) error {
+    action := fmt.Sprintf("wait for deletion of %s", resourcePath.Path())
+    forceFinalizeAt := time.Now().Add(options.ForceFinalizeGracePeriod)
+
+    eh := client.retry(action, nil, func() error {
+        liveContents, err := client.request("GET", resourcePath.Path(), nil, "", nil)
+        if err != nil {
+            return err
+        }
+
+        finalizers, err := kubernetes_model.Finalizers(liveContents)
+        if err != nil {
+            return &permanentError{err}
+        }
+
+        if options.ForceFinalize && len(finalizers) != 0 && time.Now().After(forceFinalizeAt) { // the controller is probably gone
+            log.Printf("[WARN] Removing finalizers of %s: %s", resourcePath.Path(), strings.Join(finalizers, ", "))
+            if err := client.forceFinalize(resourcePath, liveContents); err != nil {
+                return err
+            }
+        }
+
+        return errNotDeleted(finalizers)
     })
 
     if eh.error == ErrNotFound {
@@ -0,0 +1,208 @@
+package kubernetes_client
+
+import (
+    "encoding/json"
+    "fmt"
+    "github.com/maxmanuylov/go-rest/client"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
+    "strings"
+    "sync"
+)
+
+type apiResource struct {
+    Name         string   `json:"name"`
+    SingularName string   `json:"singularName"`
+    ShortNames   []string `json:"shortNames"`
+    Namespaced   bool     `json:"namespaced"`
+    Kind         string   `json:"kind"`
+}
+
+type apiResourceList struct {
+    GroupVersion string        `json:"groupVersion"`
+    Resources    []apiResource `json:"resources"`
+}
+
+type apiVersions struct {
+    Versions []string `json:"versions"`
+}
+
+type apiGroupList struct {
+    Groups []struct {
+        Name             string `json:"name"`
+        PreferredVersion struct {
+            GroupVersion string `json:"groupVersion"`
+        } `json:"preferredVersion"`
+        Versions []struct {
+            GroupVersion string `json:"groupVersion"`
+        } `json:"versions"`
+    } `json:"groups"`
+}
+
+var discoveryCache = struct {
+    sync.Mutex
+    resources map[string]map[string]*apiResource
+}{
+    resources: make(map[string]map[string]*apiResource), // shared by all the clients of the same API server
+}
+
+func (client *KubeClient) Resolve(resources ...*kubernetes_model.KubeResource) error {
+    for _, resource := range resources {
+        apiResource, err := client.discoverResource(resource.ApiPath, resource.Kind)
+        if err != nil {
+            return err
+        }
+
+        if apiResource != nil {
+            resource.SetScope(apiResource.Name, apiResource.Namespaced)
+        }
+
+        resource.SetDefaultNamespace(client.namespace)
+    }
+
+    return nil
+}
+
+func (client *KubeClient) FindResource(namespace, kind, name string) (*kubernetes_model.KubeResourcePath, error) {
+    apiPaths, err := client.preferredApiPaths()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, apiPath := range apiPaths {
+        resources, err := client.fetchApiResources(apiPath)
+        if err != nil {
+            return nil, err
+        }
+
+        for _, resource := range resources {
+            if !resource.matches(kind) {
+                continue
+            }
+
+            resourcePath := &kubernetes_model.KubeResourcePath{
+                ApiPath:    apiPath,
+                Namespace:  "",
+                Collection: resource.Name,
+                Name:       name,
+            }
+
+            if resource.Namespaced {
+                if namespace == "" {
+                    namespace = client.namespace
+                }
+                resourcePath.Namespace = namespace
+            } else if namespace != "" {
+                return nil, fmt.Errorf("%s is not namespaced, use \"%s/%s\" instead", resource.Kind, kind, name)
+            }
+
+            return resourcePath, nil
+        }
+    }
+
+    return nil, fmt.Errorf("Kind %s is not served by the API server", kind)
+}
+
+func (resource *apiResource) matches(kind string) bool {
+    if strings.EqualFold(resource.Kind, kind) || strings.EqualFold(resource.Name, kind) || strings.EqualFold(resource.SingularName, kind) {
+        return true
+    }
+    for _, shortName := range resource.ShortNames {
+        if strings.EqualFold(shortName, kind) {
+            return true
+        }
+    }
+    return false
+}
+
+func (client *KubeClient) preferredApiPaths() ([]string, error) {
+    versions := &apiVersions{}
+    if err := client.discover("api", versions); err != nil {
+        return nil, err
+    }
+
+    groups := &apiGroupList{}
+    if err := client.discover("apis", groups); err != nil {
+        return nil, err
+    }
+
+    apiPaths := make([]string, 0, len(versions.Versions) + len(groups.Groups))
+
+    for _, version := range versions.Versions {
+        apiPaths = append(apiPaths, fmt.Sprintf("api/%s", version))
+    }
+
+    for _, group := range groups.Groups {
+        apiPaths = append(apiPaths, fmt.Sprintf("apis/%s", group.PreferredVersion.GroupVersion))
+    }
+
+    return apiPaths, nil
+}
+
+func (client *KubeClient) discover(path string, document interface{}) error {
+    action := fmt.Sprintf("discover %s", path)
+
+    var body []byte
+    eh := client.retry(action, nil, func() error {
+        var err error
+        body, err = client.restClient.Do("GET", path, rest_client.Json, nil)
+        return err
+    })
+
+    if eh.error != nil {
+        return eh.error
+    }
+
+    if err := json.Unmarshal(body, document); err != nil {
+        return fmt.Errorf("Failed to %s: %v", action, err)
+    }
+
+    return nil
+}
+
+func (client *KubeClient) discoverResource(apiPath, kind string) (*apiResource, error) {
+    cacheKey := fmt.Sprintf("%s/%s", client.apiServer, apiPath)
+
+    discoveryCache.Lock()
+    resources, cached := discoveryCache.resources[cacheKey]
+    discoveryCache.Unlock()
+
+    if cached {
+        if resource, ok := resources[kind]; ok {
+            return resource, nil
+        }
+    }
+
+    // The kind may be served since recently (e.g. a CRD created by another resource), so the cache is refreshed
+    resources, err := client.fetchApiResources(apiPath)
+    if err != nil {
+        return nil, err
+    }
+
+    discoveryCache.Lock()
+    discoveryCache.resources[cacheKey] = resources
+    discoveryCache.Unlock()
+
+    return resources[kind], nil
+}
+
+func (client *KubeClient) fetchApiResources(apiPath string) (map[string]*apiResource, error) {
+    resourceList := &apiResourceList{}
+
+    if err := client.discover(apiPath, resourceList); err == ErrNotFound { // group version is not served (yet), resource path is guessed then
+        return map[string]*apiResource{}, nil
+    } else if err != nil {
+        return nil, err
+    }
+
+    resources := make(map[string]*apiResource, len(resourceList.Resources))
+
+    for i := range resourceList.Resources {
+        resource := &resourceList.Resources[i]
+        if strings.Contains(resource.Name, "/") { // subresource
+            continue
+        }
+        resources[resource.Kind] = resource
+    }
+
+    return resources, nil
+}
@@ -0,0 +1,108 @@
+package kubernetes_client
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "github.com/aws/aws-sdk-go/aws"
+    "github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+    "github.com/aws/aws-sdk-go/aws/session"
+    "github.com/aws/aws-sdk-go/service/sts"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
+    "net/http"
+    "sync"
+    "time"
+)
+
+const (
+    eksTokenPrefix         = "k8s-aws-v1."
+    eksClusterIdHeader     = "x-k8s-aws-id"
+    eksPresignExpiry       = 60 * time.Second // X-Amz-Expires, EKS accepts the token for 15 minutes regardless
+    eksTokenLifetime       = 15 * time.Minute
+    eksTokenRefreshAdvance = time.Minute
+)
+
+type eksCredentials struct {
+    sync.Mutex
+    config    *kubernetes_cluster.EksConfig
+    token     string
+    expiresAt time.Time
+}
+
+var eksCredentialsCache = struct {
+    sync.Mutex
+    credentials map[string]*eksCredentials
+}{
+    credentials: make(map[string]*eksCredentials), // the token is shared by all the clients of the cluster
+}
+
+func newEksCredentials(config *kubernetes_cluster.EksConfig) *eksCredentials {
+    key, _ := json.Marshal(config)
+
+    eksCredentialsCache.Lock()
+    defer eksCredentialsCache.Unlock()
+
+    credentials, ok := eksCredentialsCache.credentials[string(key)]
+    if !ok {
+        credentials = &eksCredentials{config: config}
+        eksCredentialsCache.credentials[string(key)] = credentials
+    }
+
+    return credentials
+}
+
+func (c *eksCredentials) authorize(request *http.Request) error {
+    c.Lock()
+    defer c.Unlock()
+
+    if c.token == "" || !time.Now().Add(eksTokenRefreshAdvance).Before(c.expiresAt) { // long waits outlive a single token
+        issuedAt := time.Now()
+
+        token, err := c.generateToken()
+        if err != nil {
+            return err
+        }
+
+        c.token, c.expiresAt = token, issuedAt.Add(eksTokenLifetime)
+    }
+
+    setBearerToken(request, c.token)
+    return nil
+}
+
+func (c *eksCredentials) invalidate() {
+    c.Lock()
+    defer c.Unlock()
+
+    c.token = ""
+}
+
+func (c *eksCredentials) generateToken() (string, error) {
+    awsConfig := aws.Config{}
+    if c.config.Region != "" {
+        awsConfig.Region = aws.String(c.config.Region)
+    }
+
+    awsSession, err := session.NewSessionWithOptions(session.Options{ // standard credential chain: env, shared config, instance role
+        Config:            awsConfig,
+        SharedConfigState: session.SharedConfigEnable,
+    })
+    if err != nil {
+        return "", fmt.Errorf("Failed to create AWS session: %v", err)
+    }
+
+    stsConfig := &aws.Config{}
+    if c.config.RoleArn != "" {
+        stsConfig.Credentials = stscreds.NewCredentials(awsSession, c.config.RoleArn)
+    }
+
+    request, _ := sts.New(awsSession, stsConfig).GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+    request.HTTPRequest.Header.Add(eksClusterIdHeader, c.config.ClusterName)
+
+    presignedUrl, err := request.Presign(eksPresignExpiry)
+    if err != nil {
+        return "", fmt.Errorf("Failed to presign STS GetCallerIdentity request for EKS cluster %s: %v", c.config.ClusterName, err)
+    }
+
+    return eksTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(presignedUrl)), nil
+}
@@ -0,0 +1,175 @@
+package kubernetes_client
+
+import (
+    "bytes"
+    "crypto/tls"
+    "encoding/json"
+    "fmt"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
+    "net/http"
+    "os"
+    "os/exec"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    execCredentialKind       = "ExecCredential"
+    execCredentialExpirySkew = 30 * time.Second
+)
+
+type execCredential struct {
+    ApiVersion string `json:"apiVersion"`
+    Kind       string `json:"kind"`
+    Spec       struct {
+        Interactive bool `json:"interactive"`
+    } `json:"spec"`
+    Status *struct {
+        ExpirationTimestamp   *time.Time `json:"expirationTimestamp"`
+        Token                 string     `json:"token"`
+        ClientCertificateData string     `json:"clientCertificateData"`
+        ClientKeyData         string     `json:"clientKeyData"`
+    } `json:"status,omitempty"`
+}
+
+type execCredentials struct {
+    sync.Mutex
+    config     *kubernetes_cluster.ExecConfig
+    loaded     bool
+    token      string
+    clientCert *tls.Certificate
+    expiresAt  time.Time // zero means the credential never expires
+}
+
+var execCredentialsCache = struct {
+    sync.Mutex
+    credentials map[string]*execCredentials
+}{
+    credentials: make(map[string]*execCredentials), // plugins are slow, so the credentials are shared by all the clients
+}
+
+func newExecCredentials(config *kubernetes_cluster.ExecConfig) *execCredentials {
+    key, _ := json.Marshal(config)
+
+    execCredentialsCache.Lock()
+    defer execCredentialsCache.Unlock()
+
+    credentials, ok := execCredentialsCache.credentials[string(key)]
+    if !ok {
+        credentials = &execCredentials{config: config}
+        execCredentialsCache.credentials[string(key)] = credentials
+    }
+
+    return credentials
+}
+
+func (c *execCredentials) authorize(request *http.Request) error {
+    c.Lock()
+    defer c.Unlock()
+
+    if err := c.refresh(); err != nil {
+        return err
+    }
+
+    if c.token != "" {
+        setBearerToken(request, c.token)
+    }
+
+    return nil
+}
+
+func (c *execCredentials) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+    c.Lock()
+    defer c.Unlock()
+
+    if err := c.refresh(); err != nil {
+        return nil, err
+    }
+
+    if c.clientCert == nil {
+        return &tls.Certificate{}, nil // no certificate is sent
+    }
+
+    return c.clientCert, nil
+}
+
+func (c *execCredentials) invalidate() {
+    c.Lock()
+    defer c.Unlock()
+
+    c.loaded = false
+}
+
+func (c *execCredentials) refresh() error {
+    if c.loaded && (c.expiresAt.IsZero() || time.Now().Add(execCredentialExpirySkew).Before(c.expiresAt)) {
+        return nil
+    }
+
+    credential, err := c.run()
+    if err != nil {
+        return err
+    }
+
+    c.token = credential.Status.Token
+    c.clientCert = nil
+    c.expiresAt = time.Time{}
+
+    if credential.Status.ClientCertificateData != "" || credential.Status.ClientKeyData != "" {
+        clientCert, err := tls.X509KeyPair([]byte(credential.Status.ClientCertificateData), []byte(credential.Status.ClientKeyData))
+        if err != nil {
+            return fmt.Errorf("Invalid client certificate returned by exec plugin %s: %v", c.config.Command, err)
+        }
+        c.clientCert = &clientCert
+    }
+
+    if credential.Status.ExpirationTimestamp != nil {
+        c.expiresAt = *credential.Status.ExpirationTimestamp
+    }
+
+    c.loaded = true
+
+    return nil
+}
+
+func (c *execCredentials) run() (*execCredential, error) {
+    info := &execCredential{
+        ApiVersion: c.config.ApiVersion,
+        Kind:       execCredentialKind,
+    }
+
+    encodedInfo, err := json.Marshal(info)
+    if err != nil {
+        return nil, err
+    }
+
+    command := exec.Command(c.config.Command, c.config.Args...)
+    command.Env = os.Environ()
+    for name, value := range c.config.Env {
+        command.Env = append(command.Env, fmt.Sprintf("%s=%s", name, value))
+    }
+    command.Env = append(command.Env, fmt.Sprintf("KUBERNETES_EXEC_INFO=%s", encodedInfo))
+
+    stderr := &bytes.Buffer{}
+    command.Stderr = stderr
+
+    output, err := command.Output()
+    if err != nil {
+        return nil, fmt.Errorf("Exec plugin %s failed: %v: %s", c.config.Command, err, strings.TrimSpace(stderr.String()))
+    }
+
+    credential := &execCredential{}
+    if err := json.Unmarshal(output, credential); err != nil {
+        return nil, fmt.Errorf("Failed to parse the output of exec plugin %s: %v", c.config.Command, err)
+    }
+
+    if credential.Kind != execCredentialKind || credential.ApiVersion != c.config.ApiVersion {
+        return nil, fmt.Errorf("Exec plugin %s returned %s of %s, expected %s of %s", c.config.Command, credential.Kind, credential.ApiVersion, execCredentialKind, c.config.ApiVersion)
+    }
+
+    if credential.Status == nil || (credential.Status.Token == "" && credential.Status.ClientCertificateData == "") {
+        return nil, fmt.Errorf("Exec plugin %s returned neither a token nor a client certificate", c.config.Command)
+    }
+
+    return credential, nil
+}
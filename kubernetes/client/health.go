@@ -0,0 +1,118 @@
+package kubernetes_client
+
+import (
+    "fmt"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
+    "net/http"
+    "net/url"
+    "strings"
+)
+
+func (client *KubeClient) WaitForClusterHealth(gate *kubernetes_model.HealthGate) error {
+    if gate.Readyz {
+        if err := client.waitForReadyz(); err != nil {
+            return err
+        }
+    }
+
+    if gate.MinReadyNodes > 0 {
+        if err := client.waitForNodes(gate.MinReadyNodes); err != nil {
+            return err
+        }
+    }
+
+    for _, name := range gate.SystemDeployments {
+        if err := client.waitForSystemDeployment(name); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+func (client *KubeClient) waitForReadyz() error {
+    action := "wait for Kubernetes API server to become ready"
+
+    path := "readyz"
+
+    eh := client.retry(action, nil, func() error {
+        _, err := client.request("GET", path, url.Values{"verbose": {""}}, "", nil)
+
+        if statusErr, ok := err.(*StatusError); ok {
+            if statusErr.Code == http.StatusNotFound && path == "readyz" { // served since Kubernetes 1.16
+                path = "healthz"
+                return errNotReady("/readyz is not served, falling back to /healthz")
+            }
+            if failed := kubernetes_model.FailedHealthChecks(statusErr.body); len(failed) != 0 {
+                return errNotReady(fmt.Sprintf("failed checks: %s", strings.Join(failed, "; ")))
+            }
+        }
+
+        return err
+    })
+
+    if eh.error != nil {
+        dumpErrorsToFile(action, nil, eh)
+    }
+
+    return eh.error
+}
+
+func (client *KubeClient) waitForNodes(minReadyNodes int) error {
+    action := fmt.Sprintf("wait for %d Kubernetes nodes to become ready", minReadyNodes)
+
+    eh := client.retry(action, nil, func() error {
+        nodes, err := client.request("GET", fmt.Sprintf("%s/nodes", kubernetes_model.DefaultApiPath), nil, "", nil)
+        if err != nil {
+            return err
+        }
+
+        ready, total, err := kubernetes_model.CountReadyNodes(nodes)
+        if err != nil {
+            return &permanentError{err}
+        }
+
+        if ready < minReadyNodes {
+            return errNotReady(fmt.Sprintf("%d of %d registered nodes are ready, %d required", ready, total, minReadyNodes))
+        }
+
+        return nil
+    })
+
+    if eh.error != nil {
+        dumpErrorsToFile(action, nil, eh)
+    }
+
+    return eh.error
+}
+
+func (client *KubeClient) waitForSystemDeployment(name string) error {
+    path := fmt.Sprintf("apis/apps/v1/namespaces/%s/deployments/%s", kubernetes_model.SystemNamespace, name)
+    action := fmt.Sprintf("wait for %s to become available", path)
+
+    eh := client.retry(action, nil, func() error {
+        liveContents, err := client.request("GET", path, nil, "", nil)
+        if statusErr, ok := err.(*StatusError); ok && statusErr.Code == http.StatusNotFound { // not deployed by the bootstrap yet
+            return errNotReady("the deployment does not exist yet")
+        } else if err != nil {
+            return err
+        }
+
+        status, err := kubernetes_model.CheckReadiness(liveContents)
+        if err != nil {
+            return &permanentError{err}
+        }
+
+        if status != "" {
+            return errNotReady(status)
+        }
+
+        return nil
+    })
+
+    if eh.error != nil {
+        dumpErrorsToFile(action, nil, eh)
+    }
+
+    return eh.error
+}
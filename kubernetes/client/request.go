@@ -0,0 +1,90 @@
+package kubernetes_client
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "strings"
+)
+
+const (
+    contentTypeJson                = "application/json"
+    contentTypeApplyPatch          = "application/apply-patch+yaml"
+    contentTypeMergePatch          = "application/merge-patch+json"
+    contentTypeStrategicMergePatch = "application/strategic-merge-patch+json"
+)
+
+type StatusError struct {
+    Code   int
+    Status *kubeStatus
+    body   []byte
+}
+
+type kubeStatus struct {
+    Message string `json:"message"`
+    Reason  string `json:"reason"`
+    Details struct {
+        Causes []kubeStatusCause `json:"causes"`
+    } `json:"details"`
+}
+
+type kubeStatusCause struct {
+    Reason  string `json:"reason"`
+    Message string `json:"message"`
+    Field   string `json:"field"`
+}
+
+func (err *StatusError) Error() string {
+    if err.Status != nil && err.Status.Message != "" {
+        return fmt.Sprintf("%d %s: %s", err.Code, http.StatusText(err.Code), err.Status.Message)
+    }
+    return fmt.Sprintf("%d %s", err.Code, http.StatusText(err.Code))
+}
+
+func (client *KubeClient) request(method, path string, query url.Values, contentType string, body []byte) ([]byte, error) {
+    requestUrl := fmt.Sprintf("%s/%s", client.apiServer, strings.TrimPrefix(path, "/"))
+    if len(query) != 0 {
+        requestUrl = fmt.Sprintf("%s?%s", requestUrl, query.Encode())
+    }
+
+    request, err := http.NewRequest(method, requestUrl, bytes.NewReader(body))
+    if err != nil {
+        return nil, err
+    }
+
+    request.Header.Set("Accept", contentTypeJson)
+    if body != nil {
+        request.Header.Set("Content-Type", contentType)
+    }
+
+    response, err := client.httpClient.Do(request)
+    if err != nil {
+        return nil, err
+    }
+
+    defer response.Body.Close()
+
+    responseBody, err := ioutil.ReadAll(response.Body)
+    if err != nil {
+        return nil, err
+    }
+
+    if response.StatusCode < 200 || response.StatusCode > 299 {
+        statusErr := &StatusError{
+            Code: response.StatusCode,
+            body: responseBody,
+        }
+
+        status := &kubeStatus{}
+        if json.Unmarshal(responseBody, status) == nil {
+            statusErr.Status = status
+        }
+
+        return nil, statusErr
+    }
+
+    return responseBody, nil
+}
@@ -0,0 +1,109 @@
+package kubernetes_client
+
+import (
+    "fmt"
+    "math/rand"
+    "net/http"
+    "os"
+    "strconv"
+    "sync/atomic"
+    "time"
+)
+
+const (
+    DefaultRetryMaxInterval = 30 * time.Second
+    retryInitialInterval    = time.Second
+)
+
+type throttlingTransport struct {
+    transport  http.RoundTripper
+    retryAfter int64 // nanoseconds, the last "Retry-After" received
+}
+
+func (t *throttlingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+    response, err := t.transport.RoundTrip(request)
+
+    if err == nil && (response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable) {
+        if retryAfter := parseRetryAfter(response.Header.Get("Retry-After")); retryAfter > 0 {
+            atomic.StoreInt64(&t.retryAfter, int64(retryAfter))
+        }
+    }
+
+    return response, err
+}
+
+func (t *throttlingTransport) takeRetryAfter() time.Duration {
+    return time.Duration(atomic.SwapInt64(&t.retryAfter, 0))
+}
+
+func parseRetryAfter(value string) time.Duration {
+    if value == "" {
+        return 0
+    }
+
+    if seconds, err := strconv.Atoi(value); err == nil {
+        return time.Duration(seconds) * time.Second
+    }
+
+    if date, err := http.ParseTime(value); err == nil {
+        return date.Sub(time.Now())
+    }
+
+    return 0
+}
+
+func (client *KubeClient) retry(action string, contents []byte, do func() error) *errorHistory {
+    eh := &errorHistory{
+        history: make([]error, 0),
+    }
+
+    var done bool
+    interval := retryInitialInterval
+    reauthorize := client.reauthorize
+
+    for i := 0; ; i++ {
+        if i != 0 {
+            delay := client.throttling.takeRetryAfter()
+            if delay <= 0 {
+                delay = withJitter(interval)
+                if interval *= 2; interval > client.retryMaxInterval {
+                    interval = client.retryMaxInterval
+                }
+            }
+
+            if time.Now().Add(delay).After(client.deadline) {
+                eh.error = fmt.Errorf("Timeout while trying to %s, last error: %v", action, eh.error)
+                return eh
+            }
+
+            time.Sleep(delay)
+        }
+
+        client.throttling.takeRetryAfter() // only the response to this attempt matters
+
+        done, eh.error = try(do)
+        if eh.error != nil {
+            eh.history = append(eh.history, eh.error)
+        }
+
+        if done && reauthorize && isUnauthorized(eh.error) { // the credentials are invalidated already, one more attempt with the new ones
+            done, reauthorize = false, false
+        }
+
+        if done {
+            return eh
+        }
+
+        if eh.error != nil {
+            dumpErrors(os.Stderr, action, contents, eh.error)
+        }
+    }
+}
+
+func withJitter(interval time.Duration) time.Duration {
+    half := int64(interval / 2)
+    if half <= 0 {
+        return interval
+    }
+    return time.Duration(half + rand.Int63n(half + 1))
+}
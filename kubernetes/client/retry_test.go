@@ -0,0 +1,112 @@
+package kubernetes_client
+
+import (
+    "net/http"
+    "strings"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+    delays := map[string]time.Duration{
+        "":      0,
+        "5":     5 * time.Second,
+        "0":     0,
+        "later": 0,
+    }
+
+    for value, expected := range delays {
+        if delay := parseRetryAfter(value); delay != expected {
+            t.Errorf("parseRetryAfter(\"%s\") = %v, expected %v", value, delay, expected)
+        }
+    }
+
+    date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+    if delay := parseRetryAfter(date); delay <= 0 || delay > time.Minute {
+        t.Errorf("parseRetryAfter(\"%s\") = %v, expected up to a minute", date, delay)
+    }
+}
+
+func TestWithJitter(t *testing.T) {
+    for i := 0; i < 100; i++ {
+        if delay := withJitter(time.Second); delay < time.Second / 2 || delay > time.Second {
+            t.Fatalf("withJitter(1s) = %v", delay)
+        }
+    }
+
+    if delay := withJitter(1); delay != 1 {
+        t.Errorf("withJitter(1ns) = %v", delay)
+    }
+}
+
+func testRetryClient(timeout time.Duration, reauthorize bool) *KubeClient {
+    return &KubeClient{
+        deadline:         time.Now().Add(timeout),
+        retryMaxInterval: DefaultRetryMaxInterval,
+        reauthorize:      reauthorize,
+        throttling:       &throttlingTransport{},
+    }
+}
+
+// Each failed attempt imitates a "Retry-After" response, so that the test does not wait for the backoff
+func failingAttempts(client *KubeClient, retryAfter time.Duration, errs ...error) (func() error, *int) {
+    attempts := 0
+    return func() error {
+        attempts++
+        atomic.StoreInt64(&client.throttling.retryAfter, int64(retryAfter))
+        if attempts > len(errs) {
+            return nil
+        }
+        return errs[attempts - 1]
+    }, &attempts
+}
+
+func TestRetryUntilSuccess(t *testing.T) {
+    client := testRetryClient(time.Minute, false)
+    do, attempts := failingAttempts(client, time.Millisecond, &StatusError{Code: http.StatusTooManyRequests}, &StatusError{Code: http.StatusInternalServerError})
+
+    if eh := client.retry("test", nil, do); eh.error != nil {
+        t.Fatalf("unexpected error: %v", eh.error)
+    } else if len(eh.history) != 2 || *attempts != 3 {
+        t.Errorf("%d attempts with %d errors, expected 3 attempts with 2 errors", *attempts, len(eh.history))
+    }
+}
+
+func TestRetryStopsOnPermanentErrors(t *testing.T) {
+    client := testRetryClient(time.Minute, false)
+    do, attempts := failingAttempts(client, time.Millisecond, &StatusError{Code: http.StatusUnprocessableEntity})
+
+    if eh := client.retry("test", nil, do); eh.error == nil || *attempts != 1 {
+        t.Errorf("%d attempts ended with %v, expected a single failed one", *attempts, eh.error)
+    }
+}
+
+func TestRetryReauthorizesOnce(t *testing.T) {
+    unauthorized := &StatusError{Code: http.StatusUnauthorized}
+
+    client := testRetryClient(time.Minute, true)
+    do, attempts := failingAttempts(client, time.Millisecond, unauthorized, unauthorized, unauthorized)
+
+    if eh := client.retry("test", nil, do); !isUnauthorized(eh.error) || *attempts != 2 {
+        t.Errorf("%d attempts ended with %v, expected 2 unauthorized ones", *attempts, eh.error)
+    }
+
+    client = testRetryClient(time.Minute, false)
+    do, attempts = failingAttempts(client, time.Millisecond, unauthorized)
+
+    if eh := client.retry("test", nil, do); !isUnauthorized(eh.error) || *attempts != 1 {
+        t.Errorf("%d attempts ended with %v, expected 1 unauthorized one without refreshable credentials", *attempts, eh.error)
+    }
+}
+
+func TestRetryTimeout(t *testing.T) {
+    client := testRetryClient(100 * time.Millisecond, false)
+    do, attempts := failingAttempts(client, time.Second, &StatusError{Code: http.StatusServiceUnavailable})
+
+    if eh := client.retry("test", nil, do); eh.error == nil || !strings.HasPrefix(eh.error.Error(), "Timeout while trying to test") {
+        t.Errorf("expected a timeout, got %v", eh.error)
+    } else if *attempts != 1 {
+        t.Errorf("%d attempts, expected no retry after \"Retry-After\" beyond the deadline", *attempts)
+    }
+}
@@ -0,0 +1,109 @@
+package kubernetes_client
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
+    "net/http"
+    "net/url"
+    "time"
+)
+
+const DefaultRequestTimeout = 10 * time.Second
+
+var tlsVersions = map[string]uint16{
+    "1.0": tls.VersionTLS10,
+    "1.1": tls.VersionTLS11,
+    "1.2": tls.VersionTLS12,
+}
+
+func newTransport(cluster *kubernetes_cluster.Cluster, credentials credentials) (http.RoundTripper, error) {
+    certProvider, _ := credentials.(clientCertificateProvider)
+
+    tlsConfig, err := newTlsConfig(cluster, certProvider)
+    if err != nil {
+        return nil, err
+    }
+
+    proxy, err := newProxy(cluster)
+    if err != nil {
+        return nil, err
+    }
+
+    httpTransport := &http.Transport{
+        Proxy:                 proxy,
+        TLSClientConfig:       tlsConfig,
+        TLSHandshakeTimeout:   10 * time.Second,
+        IdleConnTimeout:       90 * time.Second,
+        ExpectContinueTimeout: time.Second,
+        MaxIdleConnsPerHost:   10,
+    }
+
+    if cluster.SshTunnel != nil {
+        httpTransport.DialContext = newTunnelDialer(cluster.SshTunnel)
+        if cluster.ProxyUrl == "" {
+            httpTransport.Proxy = nil // local proxy settings make no sense on the other side of the tunnel
+        }
+    }
+
+    var transport http.RoundTripper = httpTransport
+
+    if credentials != nil {
+        transport = &authTransport{transport: transport, credentials: credentials}
+    }
+
+    if cluster.Impersonate != nil {
+        transport = &impersonationTransport{transport: transport, impersonation: cluster.Impersonate}
+    }
+
+    return transport, nil
+}
+
+func newTlsConfig(cluster *kubernetes_cluster.Cluster, certProvider clientCertificateProvider) (*tls.Config, error) {
+    tlsConfig := &tls.Config{
+        InsecureSkipVerify: cluster.Insecure,
+        ServerName:         cluster.TlsServerName,
+    }
+
+    if cluster.TlsMinVersion != "" {
+        minVersion, ok := tlsVersions[cluster.TlsMinVersion]
+        if !ok {
+            return nil, fmt.Errorf("Unsupported TLS version: %s", cluster.TlsMinVersion)
+        }
+        tlsConfig.MinVersion = minVersion
+    }
+
+    if cluster.CaCert != "" && !cluster.Insecure { // the CA is used on its own, e.g. with token authentication
+        tlsConfig.RootCAs = x509.NewCertPool()
+        if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(cluster.CaCert)) {
+            return nil, errors.New("Failed to parse CA certificate")
+        }
+    }
+
+    if cluster.ClientCert != "" || cluster.ClientKey != "" {
+        clientCert, err := tls.X509KeyPair([]byte(cluster.ClientCert), []byte(cluster.ClientKey))
+        if err != nil {
+            return nil, fmt.Errorf("Invalid client certificate or key: %v", err)
+        }
+        tlsConfig.Certificates = []tls.Certificate{clientCert}
+    } else if certProvider != nil { // e.g. an exec plugin which issues client certificates
+        tlsConfig.GetClientCertificate = certProvider.clientCertificate
+    }
+
+    return tlsConfig, nil
+}
+
+func newProxy(cluster *kubernetes_cluster.Cluster) (func(*http.Request) (*url.URL, error), error) {
+    if cluster.ProxyUrl == "" {
+        return http.ProxyFromEnvironment, nil // HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+    }
+
+    proxyUrl, err := url.Parse(cluster.ProxyUrl)
+    if err != nil {
+        return nil, fmt.Errorf("Invalid proxy URL: %v", err)
+    }
+
+    return http.ProxyURL(proxyUrl), nil
+}
@@ -0,0 +1,188 @@
+package kubernetes_client
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
+    "github.com/mitchellh/go-homedir"
+    "golang.org/x/crypto/ssh"
+    "golang.org/x/crypto/ssh/agent"
+    "golang.org/x/crypto/ssh/knownhosts"
+    "io/ioutil"
+    "net"
+    "os"
+    "sync"
+    "time"
+)
+
+const (
+    defaultSshPort    = "22"
+    defaultKnownHosts = "~/.ssh/known_hosts"
+    sshDialTimeout    = 30 * time.Second
+)
+
+type sshConnection struct {
+    client    *ssh.Client
+    agentConn net.Conn // nil unless authenticated by the SSH agent
+}
+
+func (c *sshConnection) Close() {
+    c.client.Close()
+    if c.agentConn != nil {
+        c.agentConn.Close()
+    }
+}
+
+var sshTunnels = struct {
+    sync.Mutex
+    connections map[string]*sshConnection
+}{
+    connections: make(map[string]*sshConnection), // one SSH connection per tunnel is shared by all the clients
+}
+
+func newTunnelDialer(tunnel *kubernetes_cluster.SshTunnel) func(context.Context, string, string) (net.Conn, error) {
+    key, _ := json.Marshal(tunnel)
+
+    return func(_ context.Context, network, address string) (net.Conn, error) {
+        sshConn, err := getSshConnection(string(key), tunnel)
+        if err != nil {
+            return nil, err
+        }
+
+        conn, err := sshConn.client.Dial(network, address)
+        if err == nil {
+            return conn, nil
+        }
+
+        if _, ok := err.(*ssh.OpenChannelError); ok { // rejected by the bastion, the connection itself is fine
+            return nil, err
+        }
+
+        dropSshConnection(string(key), sshConn) // the connection is probably broken, reconnect on the next attempt
+
+        return nil, fmt.Errorf("Failed to dial %s through SSH tunnel %s: %v", address, tunnel.Host, err)
+    }
+}
+
+func getSshConnection(key string, tunnel *kubernetes_cluster.SshTunnel) (*sshConnection, error) {
+    sshTunnels.Lock()
+    defer sshTunnels.Unlock()
+
+    if sshConn, ok := sshTunnels.connections[key]; ok {
+        return sshConn, nil
+    }
+
+    sshConn, err := dialSsh(tunnel)
+    if err != nil {
+        return nil, err
+    }
+
+    sshTunnels.connections[key] = sshConn
+
+    return sshConn, nil
+}
+
+func dropSshConnection(key string, sshConn *sshConnection) {
+    sshTunnels.Lock()
+    defer sshTunnels.Unlock()
+
+    if sshTunnels.connections[key] == sshConn {
+        delete(sshTunnels.connections, key)
+    }
+
+    sshConn.Close()
+}
+
+func dialSsh(tunnel *kubernetes_cluster.SshTunnel) (*sshConnection, error) {
+    address := tunnel.Host
+    if _, _, err := net.SplitHostPort(address); err != nil {
+        address = net.JoinHostPort(address, defaultSshPort)
+    }
+
+    hostKeyCallback, err := sshHostKeyCallback(tunnel)
+    if err != nil {
+        return nil, err
+    }
+
+    authMethods, agentConn, err := sshAuthMethods(tunnel)
+    if err != nil {
+        return nil, err
+    }
+
+    sshClient, err := ssh.Dial("tcp", address, &ssh.ClientConfig{
+        User:            tunnel.User,
+        Auth:            authMethods,
+        HostKeyCallback: hostKeyCallback,
+        Timeout:         sshDialTimeout,
+    })
+    if err != nil {
+        if agentConn != nil {
+            agentConn.Close()
+        }
+        return nil, fmt.Errorf("Failed to open SSH tunnel to %s: %v", address, err)
+    }
+
+    return &sshConnection{client: sshClient, agentConn: agentConn}, nil
+}
+
+func sshAuthMethods(tunnel *kubernetes_cluster.SshTunnel) ([]ssh.AuthMethod, net.Conn, error) {
+    if tunnel.PrivateKey != "" {
+        signer, err := ssh.ParsePrivateKey([]byte(tunnel.PrivateKey))
+        if err != nil {
+            return nil, nil, fmt.Errorf("Invalid SSH private key: %v", err)
+        }
+        return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil, nil
+    }
+
+    socket := os.Getenv("SSH_AUTH_SOCK")
+    if socket == "" {
+        return nil, nil, errors.New("SSH tunnel requires either \"private_key\" or a running SSH agent")
+    }
+
+    agentConn, err := net.Dial("unix", socket)
+    if err != nil {
+        return nil, nil, fmt.Errorf("Failed to connect to SSH agent: %v", err)
+    }
+
+    return []ssh.AuthMethod{ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers)}, agentConn, nil // the agent is asked on every authentication
+}
+
+func sshHostKeyCallback(tunnel *kubernetes_cluster.SshTunnel) (ssh.HostKeyCallback, error) {
+    if tunnel.KnownHosts == "" {
+        path, err := homedir.Expand(defaultKnownHosts)
+        if err != nil {
+            return nil, err
+        }
+
+        hostKeyCallback, err := knownhosts.New(path)
+        if err != nil {
+            return nil, fmt.Errorf("SSH tunnel requires \"known_hosts\" to verify the host key: %v", err)
+        }
+
+        return hostKeyCallback, nil
+    }
+
+    file, err := ioutil.TempFile("", "known_hosts") // knownhosts reads files only
+    if err != nil {
+        return nil, err
+    }
+
+    defer os.Remove(file.Name())
+
+    _, err = file.WriteString(tunnel.KnownHosts)
+    if closeErr := file.Close(); err == nil {
+        err = closeErr
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    hostKeyCallback, err := knownhosts.New(file.Name())
+    if err != nil {
+        return nil, fmt.Errorf("Invalid \"known_hosts\": %v", err)
+    }
+
+    return hostKeyCallback, nil
+}
@@ -0,0 +1,94 @@
+package kubernetes_client
+
+import (
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "fmt"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
+    "golang.org/x/crypto/ssh"
+    "golang.org/x/crypto/ssh/knownhosts"
+    "net"
+    "testing"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+    privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    return publicKey
+}
+
+func knownHostsLine(patterns string, key ssh.PublicKey) string {
+    return fmt.Sprintf("%s %s", patterns, ssh.MarshalAuthorizedKey(key))
+}
+
+func checkHostKey(t *testing.T, knownHosts, address string, key ssh.PublicKey) error {
+    hostKeyCallback, err := sshHostKeyCallback(&kubernetes_cluster.SshTunnel{KnownHosts: knownHosts})
+    if err != nil {
+        t.Fatalf("known hosts are not parsed: %v", err)
+    }
+
+    return hostKeyCallback(address, &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 22}, key)
+}
+
+func TestKnownHostsMatching(t *testing.T) {
+    key, otherKey := newHostKey(t), newHostKey(t)
+
+    knownHosts := knownHostsLine("bastion.example.com", key) +
+        knownHostsLine("[bastion.example.com]:2222", otherKey) +
+        knownHostsLine(knownhosts.HashHostname("hashed.example.com"), key) +
+        knownHostsLine("*.internal,!db.internal", key)
+
+    accepted := map[string]ssh.PublicKey{
+        "bastion.example.com:22":   key,
+        "bastion.example.com:2222": otherKey,
+        "hashed.example.com:22":    key,
+        "web.internal:22":          key,
+    }
+
+    for address, hostKey := range accepted {
+        if err := checkHostKey(t, knownHosts, address, hostKey); err != nil {
+            t.Errorf("%s must be accepted: %v", address, err)
+        }
+    }
+
+    rejected := map[string]ssh.PublicKey{
+        "bastion.example.com:22":   otherKey, // key mismatch
+        "bastion.example.com:2222": key,
+        "hashed.example.com:2222":  key,
+        "db.internal:22":           key, // negated
+        "unknown.example.com:22":   key,
+    }
+
+    for address, hostKey := range rejected {
+        if err := checkHostKey(t, knownHosts, address, hostKey); err == nil {
+            t.Errorf("%s must be rejected", address)
+        }
+    }
+}
+
+func TestKnownHostsRevocation(t *testing.T) {
+    key := newHostKey(t)
+
+    knownHosts := knownHostsLine("bastion.example.com", key) + "@revoked " + knownHostsLine("*", key)
+
+    if err := checkHostKey(t, knownHosts, "bastion.example.com:22", key); err == nil {
+        t.Error("revoked key must be rejected")
+    } else if _, ok := err.(*knownhosts.RevokedError); !ok {
+        t.Errorf("expected a revocation error, got %v", err)
+    }
+}
+
+func TestInvalidKnownHosts(t *testing.T) {
+    if _, err := sshHostKeyCallback(&kubernetes_cluster.SshTunnel{KnownHosts: "bastion.example.com ssh-ed25519 not-a-key\n"}); err == nil {
+        t.Error("invalid known hosts must be rejected")
+    }
+}
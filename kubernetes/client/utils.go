@@ -1,23 +1,23 @@
 package kubernetes_client
 
 import (
+    "errors"
     "fmt"
     "github.com/maxmanuylov/go-rest/client"
     "github.com/maxmanuylov/go-rest/error"
-    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
-    "github.com/maxmanuylov/utils/http/transport/tls"
     "io"
     "net/http"
     "os"
+    "regexp"
+    "strings"
     "time"
 )
 
-func newTransport(cluster *kubernetes_cluster.Cluster) (http.RoundTripper, error) {
-    if cluster.CaCert != "" && cluster.ClientCert != "" && cluster.ClientKey != "" {
-        return tls_transport.New([]byte(cluster.CaCert), []byte(cluster.ClientCert), []byte(cluster.ClientKey))
-    }
-    return http.DefaultTransport, nil
+var applyConflictManager = regexp.MustCompile(`conflict with "([^"]*)"`)
+
+type permanentError struct {
+    error
 }
 
 type errorHistory struct {
@@ -29,6 +29,34 @@ func errInvalidEncoding(encoding string) error {
     return fmt.Errorf("Invalid encoding: %s", encoding)
 }
 
+func errNotReady(status string) error {
+    return fmt.Errorf("Not ready yet: %s", status)
+}
+
+func errNotDeleted(finalizers []string) error {
+    if len(finalizers) == 0 {
+        return errors.New("Still terminating")
+    }
+    return fmt.Errorf("Still terminating, blocked by finalizers: %s", strings.Join(finalizers, ", "))
+}
+
+func errApplyConflict(action string, statusErr *StatusError) error {
+    if statusErr.Status == nil || len(statusErr.Status.Details.Causes) == 0 {
+        return fmt.Errorf("Failed to %s: %v", action, statusErr)
+    }
+
+    conflicts := make([]string, 0, len(statusErr.Status.Details.Causes))
+    for _, cause := range statusErr.Status.Details.Causes {
+        if manager := applyConflictManager.FindStringSubmatch(cause.Message); manager != nil {
+            conflicts = append(conflicts, fmt.Sprintf("%s (managed by \"%s\")", cause.Field, manager[1]))
+        } else {
+            conflicts = append(conflicts, fmt.Sprintf("%s (%s)", cause.Field, cause.Message))
+        }
+    }
+
+    return fmt.Errorf("Failed to %s, fields are owned by other field managers: %s; set \"force_conflicts = true\" to take them over", action, strings.Join(conflicts, ", "))
+}
+
 func createResource(collection rest_client.Collection, encoding string, contents []byte) error {
     if encoding == kubernetes_model.EncodingJson {
         _, err := collection.CreateJson(contents)
@@ -49,41 +77,24 @@ func updateResource(collection rest_client.Collection, name, encoding string, co
     return errInvalidEncoding(encoding)
 }
 
-func retryLong(action string, contents []byte, do func() error) *errorHistory {
-    return retry(200, action, contents, do) // 10 minutes
-}
-
-func retryShort(action string, contents []byte, do func() error) *errorHistory {
-    return retry(3, action, contents, do) // 3 times
-}
+func updatePatch(resource *kubernetes_model.KubeResource) (string, []byte, error) {
+    var contentType string
 
-func retry(n int, action string, contents []byte, do func() error) *errorHistory {
-    eh := &errorHistory{
-        history: make([]error, 0),
+    switch resource.UpdateStrategy {
+    case kubernetes_model.UpdateStrategyMerge:
+        contentType = contentTypeMergePatch
+    case kubernetes_model.UpdateStrategyStrategic:
+        contentType = contentTypeStrategicMergePatch
+    default:
+        return "", nil, nil // full replace
     }
 
-    var done bool
-
-    for i := 0; i < n; i++ {
-        if i != 0 {
-            time.Sleep(3 * time.Second)
-        }
-
-        done, eh.error = try(do)
-        if eh.error != nil {
-            eh.history = append(eh.history, eh.error)
-        }
-
-        if done {
-            return eh
-        }
-
-        if eh.error != nil {
-            dumpErrors(os.Stderr, action, contents, eh.error)
-        }
+    patch, err := kubernetes_model.ToJson(resource.Contents, resource.Encoding)
+    if err != nil {
+        return "", nil, err
     }
 
-    return eh
+    return contentType, patch, nil
 }
 
 func try(do func() error) (bool, error) {
@@ -92,6 +103,20 @@ func try(do func() error) (bool, error) {
         return true, nil
     }
 
+    if permanentErr, ok := err.(*permanentError); ok { // no sense to retry
+        return true, permanentErr.error
+    }
+
+    if statusErr, ok := err.(*StatusError); ok {
+        if statusErr.Code == http.StatusNotFound {
+            return true, ErrNotFound
+        }
+        if statusErr.Code >= 400 && statusErr.Code < 500 && statusErr.Code != http.StatusForbidden && statusErr.Code != http.StatusTooManyRequests {
+            return true, statusErr // conflicts are returned as is since they carry the details
+        }
+        return false, statusErr
+    }
+
     restErr, ok := err.(*rest_error.Error)
     if !ok {
         return false, err
@@ -107,12 +132,25 @@ func try(do func() error) (bool, error) {
         if restErr.Code == http.StatusForbidden { // Illegal Kubernetes state, need to retry
             return false, restErr
         }
+        if restErr.Code == http.StatusTooManyRequests { // throttled by API Priority and Fairness
+            return false, restErr
+        }
         return true, restErr
     }
 
     return false, restErr
 }
 
+func isUnauthorized(err error) bool {
+    if statusErr, ok := err.(*StatusError); ok {
+        return statusErr.Code == http.StatusUnauthorized
+    }
+    if restErr, ok := err.(*rest_error.Error); ok {
+        return restErr.Code == http.StatusUnauthorized
+    }
+    return false
+}
+
 func dumpErrorsToFile(action string, contents []byte, eh *errorHistory) {
     file, err := os.Create("kubernetes-error.log")
     if err != nil {
@@ -0,0 +1,53 @@
+package kubernetes_client
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+type serverVersion struct {
+    Major      string `json:"major"`
+    Minor      string `json:"minor"`
+    GitVersion string `json:"gitVersion"`
+    Platform   string `json:"platform"`
+}
+
+type ServerInfo struct {
+    Version     string // "<major>.<minor>"
+    GitVersion  string
+    Platform    string
+    ApiVersions []string // served group versions, e.g. "v1" and "apps/v1"
+}
+
+func (client *KubeClient) ServerInfo() (*ServerInfo, error) {
+    version := &serverVersion{}
+    if err := client.discover("version", version); err != nil {
+        return nil, err
+    }
+
+    versions := &apiVersions{}
+    if err := client.discover("api", versions); err != nil {
+        return nil, err
+    }
+
+    groups := &apiGroupList{}
+    if err := client.discover("apis", groups); err != nil {
+        return nil, err
+    }
+
+    apiVersions := append([]string{}, versions.Versions...)
+    for _, group := range groups.Groups {
+        for _, groupVersion := range group.Versions {
+            apiVersions = append(apiVersions, groupVersion.GroupVersion)
+        }
+    }
+    sort.Strings(apiVersions)
+
+    return &ServerInfo{
+        Version:     fmt.Sprintf("%s.%s", version.Major, strings.TrimSuffix(version.Minor, "+")), // managed clusters report minor versions like "29+"
+        GitVersion:  version.GitVersion,
+        Platform:    version.Platform,
+        ApiVersions: apiVersions,
+    }, nil
+}
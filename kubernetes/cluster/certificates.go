@@ -0,0 +1,84 @@
+package kubernetes_cluster
+
+import (
+    "crypto/x509"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+)
+
+const DefaultCertExpiryWarning = 30 * 24 * time.Hour
+
+func (c *Cluster) ClientCertNotAfter() (time.Time, error) {
+    return certificatesNotAfter(c.ClientCert)
+}
+
+func (c *Cluster) CaCertNotAfter() (time.Time, error) {
+    return certificatesNotAfter(c.CaCert)
+}
+
+func (c *Cluster) CheckCertificates() error { // expired credentials otherwise end up in opaque TLS errors after all the retries
+    warning, ok := parseDuration(c.CertExpiryWarning)
+    if !ok {
+        warning = DefaultCertExpiryWarning
+    }
+
+    certificates := []struct {
+        attribute string
+        notAfter  func() (time.Time, error)
+    }{
+        {"client_cert", c.ClientCertNotAfter},
+        {"ca_cert", c.CaCertNotAfter},
+    }
+
+    now := time.Now()
+
+    for _, certificate := range certificates {
+        notAfter, err := certificate.notAfter()
+        if err != nil || notAfter.IsZero() { // not specified, invalid PEM is reported by the TLS configuration
+            continue
+        }
+
+        if now.After(notAfter) {
+            return fmt.Errorf("Certificate \"%s\" of Kubernetes API server %s has expired at %s", certificate.attribute, c.ApiServer, notAfter.Format(time.RFC3339))
+        }
+
+        if now.Add(warning).After(notAfter) {
+            log.Printf("[WARN] Certificate \"%s\" of Kubernetes API server %s expires at %s, in %s", certificate.attribute, c.ApiServer, notAfter.Format(time.RFC3339), notAfter.Sub(now).Truncate(time.Minute))
+        }
+    }
+
+    return nil
+}
+
+func certificatesNotAfter(pemData string) (time.Time, error) { // the earliest expiry of the bundle
+    var notAfter time.Time
+
+    rest := []byte(pemData)
+    for {
+        var block *pem.Block
+        if block, rest = pem.Decode(rest); block == nil {
+            break
+        }
+        if block.Type != "CERTIFICATE" {
+            continue
+        }
+
+        certificate, err := x509.ParseCertificate(block.Bytes)
+        if err != nil {
+            return time.Time{}, err
+        }
+
+        if notAfter.IsZero() || certificate.NotAfter.Before(notAfter) {
+            notAfter = certificate.NotAfter
+        }
+    }
+
+    if notAfter.IsZero() && pemData != "" {
+        return time.Time{}, errors.New("No PEM encoded certificate found")
+    }
+
+    return notAfter, nil
+}
@@ -1,39 +1,349 @@
 package kubernetes_cluster
 
 import (
+    "bytes"
     "github.com/hashicorp/terraform/helper/schema"
     "encoding/json"
+    "errors"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
+    "time"
 )
 
+const DefaultExecApiVersion = "client.authentication.k8s.io/v1beta1"
+
+var ExecApiVersions = []string{
+    "client.authentication.k8s.io/v1",
+    DefaultExecApiVersion,
+    "client.authentication.k8s.io/v1alpha1",
+}
+
+var TimeoutKeys = []string{
+    schema.TimeoutCreate,
+    schema.TimeoutRead,
+    schema.TimeoutUpdate,
+    schema.TimeoutDelete,
+}
+
+type ExecConfig struct {
+    ApiVersion string
+    Command    string
+    Args       []string          `json:",omitempty"`
+    Env        map[string]string `json:",omitempty"`
+}
+
+type EksConfig struct {
+    ClusterName string
+    Region      string `json:",omitempty"`
+    RoleArn     string `json:",omitempty"`
+}
+
+type Impersonation struct {
+    User   string
+    Groups []string            `json:",omitempty"`
+    Extra  map[string][]string `json:",omitempty"`
+}
+
+type SshTunnel struct {
+    Host       string
+    User       string
+    PrivateKey string `json:",omitempty"`
+    KnownHosts string `json:",omitempty"`
+}
+
+type ProviderConfig struct {
+    Cluster       *Cluster
+    EncryptionKey string // "cluster" blobs of "k8s_cluster" resources are encrypted with it
+}
+
 type Cluster struct {
-    ApiServer  string
-    CaCert     string
-    ClientCert string
-    ClientKey  string
+    ApiServer         string
+    CaCert            string
+    ClientCert        string
+    ClientKey         string
+    Insecure          bool                               `json:",omitempty"`
+    TlsServerName     string                             `json:",omitempty"`
+    TlsMinVersion     string                             `json:",omitempty"`
+    ProxyUrl          string                             `json:",omitempty"`
+    RequestTimeout    string                             `json:",omitempty"`
+    CertExpiryWarning string                             `json:",omitempty"`
+    Token             string                             `json:",omitempty"`
+    TokenFile         string                             `json:",omitempty"`
+    Username          string                             `json:",omitempty"`
+    Password          string                             `json:",omitempty"`
+    Exec              *ExecConfig                        `json:",omitempty"`
+    Eks               *EksConfig                         `json:",omitempty"`
+    Impersonate       *Impersonation                     `json:",omitempty"`
+    SshTunnel         *SshTunnel                         `json:",omitempty"`
+    Namespace         string                             `json:",omitempty"`
+    Timeouts          map[string]string                  `json:",omitempty"`
+    RetryMaxInterval  string                             `json:",omitempty"`
+    Protection        *kubernetes_model.ProtectedObjects `json:",omitempty"`
+}
+
+func New(clusterData *schema.ResourceData) (*Cluster, error) {
+    cluster, err := loadConfig(clusterData)
+    if err != nil {
+        return nil, err
+    }
+
+    override(&cluster.ApiServer, clusterData.Get("api_server").(string)) // explicit attributes take precedence over the config
+    override(&cluster.CaCert, clusterData.Get("ca_cert").(string))
+    override(&cluster.ClientCert, clusterData.Get("client_cert").(string))
+    override(&cluster.ClientKey, clusterData.Get("client_key").(string))
+    override(&cluster.TlsServerName, clusterData.Get("tls_server_name").(string))
+    override(&cluster.TlsMinVersion, clusterData.Get("tls_min_version").(string))
+    override(&cluster.ProxyUrl, clusterData.Get("proxy_url").(string))
+    override(&cluster.RequestTimeout, clusterData.Get("request_timeout").(string))
+    override(&cluster.Namespace, clusterData.Get("namespace").(string))
+
+    if clusterData.Get("insecure").(bool) {
+        cluster.Insecure = true
+    }
+
+    if impersonation := newImpersonation(clusterData); impersonation != nil {
+        cluster.Impersonate = impersonation
+    }
+
+    if cluster.Impersonate != nil && cluster.Impersonate.User == "" {
+        return nil, errors.New("\"impersonate_user\" must be set to impersonate groups or extra fields")
+    }
+
+    if sshTunnel := newSshTunnel(clusterData); sshTunnel != nil {
+        cluster.SshTunnel = sshTunnel
+    }
+
+    exec, eks := newExecConfig(clusterData), newEksConfig(clusterData)
+    if exec != nil || eks != nil || clusterData.Get("token").(string) != "" || clusterData.Get("token_file").(string) != "" || clusterData.Get("username").(string) != "" {
+        cluster.Token, cluster.TokenFile, cluster.Username, cluster.Password = "", "", "", "" // explicit credentials replace the ones from the config
+        cluster.Exec, cluster.Eks = exec, eks
+    }
+
+    override(&cluster.Token, clusterData.Get("token").(string))
+    override(&cluster.TokenFile, clusterData.Get("token_file").(string))
+    override(&cluster.Username, clusterData.Get("username").(string))
+    override(&cluster.Password, clusterData.Get("password").(string))
+
+    timeouts := make(map[string]string)
+    for _, key := range TimeoutKeys {
+        if timeout := clusterData.Get(TimeoutAttribute(key)).(string); timeout != "" {
+            timeouts[key] = timeout
+        }
+    }
+
+    cluster.Timeouts = timeouts
+    cluster.RetryMaxInterval = clusterData.Get("retry_max_interval").(string)
+    cluster.CertExpiryWarning = clusterData.Get("cert_expiry_warning").(string)
+    cluster.Protection = newProtectedObjects(clusterData)
+
+    return cluster, nil
+}
+
+func loadConfig(clusterData *schema.ResourceData) (*Cluster, error) {
+    if configPath := clusterData.Get("config_path").(string); configPath != "" {
+        return loadKubeConfig(
+            configPath,
+            clusterData.Get("config_context").(string),
+            clusterData.Get("config_context_cluster").(string),
+            clusterData.Get("config_context_user").(string),
+        )
+    }
+
+    if clusterData.Get("api_server").(string) == "" {
+        if cluster, ok, err := loadInClusterConfig(); ok || err != nil {
+            return cluster, err
+        }
+    }
+
+    return &Cluster{}, nil
+}
+
+func override(target *string, value string) {
+    if value != "" {
+        *target = value
+    }
+}
+
+func newImpersonation(clusterData *schema.ResourceData) *Impersonation {
+    user := clusterData.Get("impersonate_user").(string)
+    groups := toStrings(clusterData.Get("impersonate_groups"))
+    rawExtra := clusterData.Get("impersonate_extra").(map[string]interface{})
+
+    if user == "" && len(groups) == 0 && len(rawExtra) == 0 {
+        return nil
+    }
+
+    extra := make(map[string][]string)
+    for key, value := range rawExtra {
+        if value, ok := value.(string); ok {
+            extra[key] = []string{value}
+        }
+    }
+
+    return &Impersonation{
+        User:   user,
+        Groups: groups,
+        Extra:  extra,
+    }
 }
 
-func New(clusterData *schema.ResourceData) *Cluster {
-    return &Cluster{
-        ApiServer:  clusterData.Get("api_server").(string),
-        CaCert:     clusterData.Get("ca_cert").(string),
-        ClientCert: clusterData.Get("client_cert").(string),
-        ClientKey:  clusterData.Get("client_key").(string),
+func newSshTunnel(clusterData *schema.ResourceData) *SshTunnel {
+    blocks := clusterData.Get("ssh_tunnel").([]interface{})
+    if len(blocks) == 0 || blocks[0] == nil {
+        return nil
+    }
+
+    block := blocks[0].(map[string]interface{})
+
+    return &SshTunnel{
+        Host:       block["host"].(string),
+        User:       block["user"].(string),
+        PrivateKey: block["private_key"].(string),
+        KnownHosts: block["known_hosts"].(string),
+    }
+}
+
+func newEksConfig(clusterData *schema.ResourceData) *EksConfig {
+    blocks := clusterData.Get("eks").([]interface{})
+    if len(blocks) == 0 || blocks[0] == nil {
+        return nil
+    }
+
+    block := blocks[0].(map[string]interface{})
+
+    return &EksConfig{
+        ClusterName: block["cluster_name"].(string),
+        Region:      block["region"].(string),
+        RoleArn:     block["role_arn"].(string),
+    }
+}
+
+func newExecConfig(clusterData *schema.ResourceData) *ExecConfig {
+    blocks := clusterData.Get("exec").([]interface{})
+    if len(blocks) == 0 || blocks[0] == nil {
+        return nil
+    }
+
+    block := blocks[0].(map[string]interface{})
+
+    env := make(map[string]string)
+    if rawEnv, ok := block["env"].(map[string]interface{}); ok {
+        for name, value := range rawEnv {
+            env[name], _ = value.(string)
+        }
+    }
+
+    return &ExecConfig{
+        ApiVersion: block["api_version"].(string),
+        Command:    block["command"].(string),
+        Args:       toStrings(block["args"]),
+        Env:        env,
     }
 }
 
+func newProtectedObjects(clusterData *schema.ResourceData) *kubernetes_model.ProtectedObjects {
+    blocks := clusterData.Get("protected_objects").([]interface{})
+    if len(blocks) == 0 || blocks[0] == nil {
+        return nil
+    }
+
+    block := blocks[0].(map[string]interface{})
+
+    return &kubernetes_model.ProtectedObjects{
+        Namespaces:     toStrings(block["namespaces"]),
+        Kinds:          toStrings(block["kinds"]),
+        LabelSelectors: toStrings(block["label_selectors"]),
+        OnDestroy:      block["on_destroy"].(string),
+    }
+}
+
+func toStrings(value interface{}) []string {
+    rawValues, _ := value.([]interface{})
+
+    values := make([]string, 0, len(rawValues))
+    for _, rawValue := range rawValues {
+        if value, ok := rawValue.(string); ok && value != "" {
+            values = append(values, value)
+        }
+    }
+
+    return values
+}
+
+func (c *Cluster) ProtectedObjects() *kubernetes_model.ProtectedObjects {
+    if c.Protection == nil {
+        return kubernetes_model.DefaultProtectedObjects
+    }
+    return c.Protection
+}
+
+func TimeoutAttribute(key string) string {
+    return key + "_timeout"
+}
+
+func (c *Cluster) DefaultTimeout(key string) (time.Duration, bool) {
+    return parseDuration(c.Timeouts[key])
+}
+
+func (c *Cluster) RequestTimeoutDuration() (time.Duration, bool) {
+    return parseDuration(c.RequestTimeout)
+}
+
+func (c *Cluster) RetryMaxIntervalDuration() (time.Duration, bool) {
+    return parseDuration(c.RetryMaxInterval)
+}
+
+func parseDuration(value string) (time.Duration, bool) {
+    duration, err := time.ParseDuration(value)
+    if err != nil || duration <= 0 {
+        return 0, false
+    }
+    return duration, true
+}
+
 func Load(resourceData *schema.ResourceData, meta interface{}) (*Cluster, error) {
+    config := meta.(*ProviderConfig)
     if encodedCluster := resourceData.Get("cluster").(string); encodedCluster != "" {
-        return Decode(encodedCluster)
+        return Decode(encodedCluster, config.EncryptionKey)
     }
-    return meta.(*Cluster), nil
+    return config.Cluster, nil
 }
 
-func (c *Cluster) Encode() (string, error) {
+func (c *Cluster) Encode(encryptionKey string) (string, error) {
     encodedCluster, err := json.Marshal(c)
-    return string(encodedCluster), err
+    if err != nil || encryptionKey == "" {
+        return string(encodedCluster), err
+    }
+    return encrypt(encodedCluster, encryptionKey)
+}
+
+// Reencode keeps the previously encoded value if it holds the same cluster encrypted the same way,
+// since the encryption is randomized and a new value makes all the resources referencing the cluster change
+func (c *Cluster) Reencode(previous, encryptionKey string) (string, error) {
+    if previous != "" && IsEncrypted(previous) == (encryptionKey != "") {
+        encodedCluster, err := json.Marshal(c)
+        if err != nil {
+            return "", err
+        }
+        if data, err := decode(previous, encryptionKey); err == nil && bytes.Equal(data, encodedCluster) {
+            return previous, nil
+        }
+    }
+    return c.Encode(encryptionKey)
 }
 
-func Decode(encodedCluster string) (*Cluster, error) {
+func Decode(encodedCluster, encryptionKey string) (*Cluster, error) {
+    data, err := decode(encodedCluster, encryptionKey)
+    if err != nil {
+        return nil, err
+    }
+
     cluster := &Cluster{}
-    return cluster, json.Unmarshal([]byte(encodedCluster), cluster)
+    return cluster, json.Unmarshal(data, cluster)
+}
+
+func decode(encodedCluster, encryptionKey string) ([]byte, error) {
+    if IsEncrypted(encodedCluster) {
+        return decrypt(encodedCluster, encryptionKey)
+    }
+    return []byte(encodedCluster), nil
 }
@@ -0,0 +1,122 @@
+package kubernetes_cluster
+
+import (
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/base64"
+    "errors"
+    "golang.org/x/crypto/scrypt"
+    "io"
+    "strings"
+    "sync"
+)
+
+const encryptedPrefix = "scrypt-aes-256-gcm:" // followed by base64 of salt, nonce and sealed data
+
+const (
+    saltSize  = 16
+    scryptN   = 32768
+    scryptR   = 8
+    scryptP   = 1
+    aesKeyLen = 32
+)
+
+var derivedKeys = struct { // every resource decrypts the same cluster, the key is derived only once per salt
+    sync.Mutex
+    keys map[string][]byte
+}{
+    keys: make(map[string][]byte),
+}
+
+func encrypt(plaintext []byte, key string) (string, error) {
+    salt := make([]byte, saltSize)
+    if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+        return "", err
+    }
+
+    aead, err := newAead(key, salt)
+    if err != nil {
+        return "", err
+    }
+
+    nonce := make([]byte, aead.NonceSize())
+    if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+        return "", err
+    }
+
+    sealed := aead.Seal(append(salt, nonce...), nonce, plaintext, nil)
+
+    return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decrypt(encoded, key string) ([]byte, error) {
+    if key == "" {
+        return nil, errors.New("Cluster is encrypted, \"cluster_encryption_key\" must be specified in the provider configuration")
+    }
+
+    sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encoded, encryptedPrefix))
+    if err != nil {
+        return nil, err
+    }
+
+    if len(sealed) < saltSize {
+        return nil, errors.New("Encrypted cluster is truncated")
+    }
+
+    salt, sealed := sealed[:saltSize], sealed[saltSize:]
+
+    aead, err := newAead(key, salt)
+    if err != nil {
+        return nil, err
+    }
+
+    if len(sealed) < aead.NonceSize() {
+        return nil, errors.New("Encrypted cluster is truncated")
+    }
+
+    plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+    if err != nil {
+        return nil, errors.New("Failed to decrypt cluster, \"cluster_encryption_key\" is probably wrong")
+    }
+
+    return plaintext, nil
+}
+
+func IsEncrypted(encoded string) bool {
+    return strings.HasPrefix(encoded, encryptedPrefix)
+}
+
+func newAead(key string, salt []byte) (cipher.AEAD, error) {
+    derivedKey, err := deriveKey(key, salt)
+    if err != nil {
+        return nil, err
+    }
+
+    block, err := aes.NewCipher(derivedKey)
+    if err != nil {
+        return nil, err
+    }
+
+    return cipher.NewGCM(block)
+}
+
+func deriveKey(key string, salt []byte) ([]byte, error) {
+    cacheKey := string(salt) + key
+
+    derivedKeys.Lock()
+    defer derivedKeys.Unlock()
+
+    if derivedKey, ok := derivedKeys.keys[cacheKey]; ok {
+        return derivedKey, nil
+    }
+
+    derivedKey, err := scrypt.Key([]byte(key), salt, scryptN, scryptR, scryptP, aesKeyLen)
+    if err != nil {
+        return nil, err
+    }
+
+    derivedKeys.keys[cacheKey] = derivedKey
+
+    return derivedKey, nil
+}
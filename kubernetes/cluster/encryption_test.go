@@ -0,0 +1,91 @@
+package kubernetes_cluster
+
+import (
+    "encoding/base64"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+var testCluster = &Cluster{
+    ApiServer:  "https://prod.example.com",
+    ClientCert: "client-cert",
+    ClientKey:  "client-key",
+    Namespace:  "apps",
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+    encoded, err := testCluster.Encode("passphrase")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if !IsEncrypted(encoded) || strings.Contains(encoded, "client-key") {
+        t.Fatalf("cluster is not encrypted: %s", encoded)
+    }
+
+    decoded, err := Decode(encoded, "passphrase")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if !reflect.DeepEqual(decoded, testCluster) {
+        t.Errorf("decrypted cluster %+v differs from %+v", decoded, testCluster)
+    }
+
+    if again, _ := testCluster.Encode("passphrase"); again == encoded {
+        t.Error("salt and nonce must be random, the same cluster is encrypted identically")
+    }
+}
+
+func TestDecryptionFailures(t *testing.T) {
+    encoded, err := testCluster.Encode("passphrase")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if _, err := Decode(encoded, "wrong passphrase"); err == nil || !strings.Contains(err.Error(), "probably wrong") {
+        t.Errorf("wrong key must be reported, got %v", err)
+    }
+
+    if _, err := Decode(encoded, ""); err == nil || !strings.Contains(err.Error(), "cluster_encryption_key") {
+        t.Errorf("missing key must be reported, got %v", err)
+    }
+
+    sealed, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(encoded, encryptedPrefix))
+
+    sealed[len(sealed) - 1] ^= 1
+    if _, err := Decode(encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), "passphrase"); err == nil {
+        t.Error("tampered cluster must not be decrypted")
+    }
+
+    if _, err := Decode(encryptedPrefix + base64.StdEncoding.EncodeToString(sealed[:saltSize + 4]), "passphrase"); err == nil {
+        t.Error("truncated cluster must not be decrypted")
+    }
+}
+
+func TestReencode(t *testing.T) {
+    encoded, err := testCluster.Encode("passphrase")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if reencoded, err := testCluster.Reencode(encoded, "passphrase"); err != nil || reencoded != encoded {
+        t.Errorf("unchanged cluster must keep its encrypted value, got %v", err)
+    }
+
+    changed := *testCluster
+    changed.Namespace = "other"
+    if reencoded, err := changed.Reencode(encoded, "passphrase"); err != nil || reencoded == encoded {
+        t.Errorf("changed cluster must be encrypted again, got %v", err)
+    }
+
+    plaintext, _ := testCluster.Encode("")
+    if reencoded, err := testCluster.Reencode(plaintext, "passphrase"); err != nil || !IsEncrypted(reencoded) {
+        t.Errorf("plaintext cluster must be encrypted once the key is set, got %s, %v", reencoded, err)
+    }
+
+    if reencoded, err := testCluster.Reencode(encoded, ""); err != nil || IsEncrypted(reencoded) {
+        t.Errorf("encrypted cluster must be decrypted once the key is removed, got %s, %v", reencoded, err)
+    }
+}
@@ -0,0 +1,372 @@
+package kubernetes_cluster
+
+import (
+    "encoding/base64"
+    "fmt"
+    "github.com/mitchellh/go-homedir"
+    "gopkg.in/yaml.v2"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+)
+
+const DefaultKubeConfigContextName = "terraform"
+
+var ( // variables to be replaced in tests
+    inClusterTokenFile     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+    inClusterCaCertFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+    inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+type kubeConfig struct {
+    ApiVersion     string                    `yaml:"apiVersion,omitempty"`
+    Kind           string                    `yaml:"kind,omitempty"`
+    CurrentContext string                    `yaml:"current-context"`
+    Clusters       []*kubeConfigNamedCluster `yaml:"clusters"`
+    Users          []*kubeConfigNamedUser    `yaml:"users"`
+    Contexts       []*kubeConfigNamedContext `yaml:"contexts"`
+}
+
+type kubeConfigNamedCluster struct {
+    Name    string            `yaml:"name"`
+    Cluster kubeConfigCluster `yaml:"cluster"`
+}
+
+type kubeConfigNamedUser struct {
+    Name string         `yaml:"name"`
+    User kubeConfigUser `yaml:"user"`
+}
+
+type kubeConfigNamedContext struct {
+    Name    string            `yaml:"name"`
+    Context kubeConfigContext `yaml:"context"`
+}
+
+type kubeConfigCluster struct {
+    Server                   string `yaml:"server"`
+    CertificateAuthority     string `yaml:"certificate-authority,omitempty"`
+    CertificateAuthorityData string `yaml:"certificate-authority-data,omitempty"`
+    InsecureSkipTlsVerify    bool   `yaml:"insecure-skip-tls-verify,omitempty"`
+    TlsServerName            string `yaml:"tls-server-name,omitempty"`
+    ProxyUrl                 string `yaml:"proxy-url,omitempty"`
+}
+
+type kubeConfigUser struct {
+    ClientCertificate     string              `yaml:"client-certificate,omitempty"`
+    ClientCertificateData string              `yaml:"client-certificate-data,omitempty"`
+    ClientKey             string              `yaml:"client-key,omitempty"`
+    ClientKeyData         string              `yaml:"client-key-data,omitempty"`
+    Token                 string              `yaml:"token,omitempty"`
+    TokenFile             string              `yaml:"tokenFile,omitempty"`
+    Username              string              `yaml:"username,omitempty"`
+    Password              string              `yaml:"password,omitempty"`
+    Exec                  *kubeConfigExec     `yaml:"exec,omitempty"`
+    As                    string              `yaml:"as,omitempty"`
+    AsGroups              []string            `yaml:"as-groups,omitempty"`
+    AsUserExtra           map[string][]string `yaml:"as-user-extra,omitempty"`
+}
+
+type kubeConfigExec struct {
+    ApiVersion string                  `yaml:"apiVersion"`
+    Command    string                  `yaml:"command"`
+    Args       []string                `yaml:"args,omitempty"`
+    Env        []*kubeConfigExecEnvVar `yaml:"env,omitempty"`
+}
+
+type kubeConfigExecEnvVar struct {
+    Name  string `yaml:"name"`
+    Value string `yaml:"value"`
+}
+
+type kubeConfigContext struct {
+    Cluster   string `yaml:"cluster"`
+    User      string `yaml:"user"`
+    Namespace string `yaml:"namespace,omitempty"`
+}
+
+func loadKubeConfig(path, contextName, clusterName, userName string) (*Cluster, error) {
+    path, err := homedir.Expand(path)
+    if err != nil {
+        return nil, err
+    }
+
+    data, err := ioutil.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to read kubeconfig: %v", err)
+    }
+
+    config := &kubeConfig{}
+    if err := yaml.Unmarshal(data, config); err != nil {
+        return nil, fmt.Errorf("Failed to parse kubeconfig %s: %v", path, err)
+    }
+
+    if contextName == "" {
+        contextName = config.CurrentContext
+    }
+
+    context := &kubeConfigContext{}
+    if contextName != "" {
+        found := false
+        for _, namedContext := range config.Contexts {
+            if namedContext.Name == contextName {
+                context, found = &namedContext.Context, true
+                break
+            }
+        }
+        if !found {
+            return nil, fmt.Errorf("Context \"%s\" is not found in kubeconfig %s", contextName, path)
+        }
+    }
+
+    if clusterName == "" {
+        clusterName = context.Cluster
+    }
+
+    if userName == "" {
+        userName = context.User
+    }
+
+    cluster := &Cluster{
+        Namespace: context.Namespace,
+    }
+
+    baseDir := filepath.Dir(path)
+
+    if clusterName != "" {
+        found := false
+        for _, namedCluster := range config.Clusters {
+            if namedCluster.Name == clusterName {
+                if err := namedCluster.Cluster.apply(cluster, baseDir); err != nil {
+                    return nil, err
+                }
+                found = true
+                break
+            }
+        }
+        if !found {
+            return nil, fmt.Errorf("Cluster \"%s\" is not found in kubeconfig %s", clusterName, path)
+        }
+    }
+
+    if userName != "" {
+        found := false
+        for _, namedUser := range config.Users {
+            if namedUser.Name == userName {
+                if err := namedUser.User.apply(cluster, baseDir); err != nil {
+                    return nil, err
+                }
+                found = true
+                break
+            }
+        }
+        if !found {
+            return nil, fmt.Errorf("User \"%s\" is not found in kubeconfig %s", userName, path)
+        }
+    }
+
+    return cluster, nil
+}
+
+func (kubeCluster *kubeConfigCluster) apply(cluster *Cluster, baseDir string) error {
+    cluster.ApiServer = kubeCluster.Server
+    cluster.Insecure = kubeCluster.InsecureSkipTlsVerify
+    cluster.TlsServerName = kubeCluster.TlsServerName
+    cluster.ProxyUrl = kubeCluster.ProxyUrl
+
+    caCert, err := dataOrFile(kubeCluster.CertificateAuthorityData, kubeCluster.CertificateAuthority, baseDir)
+    if err != nil {
+        return err
+    }
+    cluster.CaCert = caCert
+
+    return nil
+}
+
+func (kubeUser *kubeConfigUser) apply(cluster *Cluster, baseDir string) error {
+    clientCert, err := dataOrFile(kubeUser.ClientCertificateData, kubeUser.ClientCertificate, baseDir)
+    if err != nil {
+        return err
+    }
+
+    clientKey, err := dataOrFile(kubeUser.ClientKeyData, kubeUser.ClientKey, baseDir)
+    if err != nil {
+        return err
+    }
+
+    cluster.ClientCert = clientCert
+    cluster.ClientKey = clientKey
+    cluster.Token = kubeUser.Token
+    cluster.TokenFile = resolvePath(kubeUser.TokenFile, baseDir)
+    cluster.Username = kubeUser.Username
+    cluster.Password = kubeUser.Password
+
+    if kubeUser.As != "" || len(kubeUser.AsGroups) != 0 || len(kubeUser.AsUserExtra) != 0 {
+        cluster.Impersonate = &Impersonation{
+            User:   kubeUser.As,
+            Groups: kubeUser.AsGroups,
+            Extra:  kubeUser.AsUserExtra,
+        }
+    }
+
+    if kubeUser.Exec != nil {
+        env := make(map[string]string)
+        for _, variable := range kubeUser.Exec.Env {
+            env[variable.Name] = variable.Value
+        }
+
+        command := kubeUser.Exec.Command
+        if strings.ContainsRune(command, filepath.Separator) { // "./bin/plugin" is relative to the kubeconfig, "plugin" is looked up in PATH
+            command = resolvePath(command, baseDir)
+        }
+
+        apiVersion := kubeUser.Exec.ApiVersion
+        if apiVersion == "" {
+            apiVersion = DefaultExecApiVersion
+        }
+
+        cluster.Exec = &ExecConfig{
+            ApiVersion: apiVersion,
+            Command:    command,
+            Args:       kubeUser.Exec.Args,
+            Env:        env,
+        }
+    }
+
+    return nil
+}
+
+func (c *Cluster) KubeConfig(name string, withCredentials bool) (string, error) { // for humans and other tools
+    cluster := kubeConfigCluster{
+        Server:                c.ApiServer,
+        InsecureSkipTlsVerify: c.Insecure,
+        TlsServerName:         c.TlsServerName,
+        ProxyUrl:              c.ProxyUrl,
+    }
+    if c.CaCert != "" && !c.Insecure {
+        cluster.CertificateAuthorityData = base64.StdEncoding.EncodeToString([]byte(c.CaCert))
+    }
+
+    user := kubeConfigUser{
+        TokenFile: c.TokenFile,
+    }
+    if withCredentials {
+        user.Token, user.Username, user.Password = c.Token, c.Username, c.Password
+        if c.ClientCert != "" && c.ClientKey != "" {
+            user.ClientCertificateData = base64.StdEncoding.EncodeToString([]byte(c.ClientCert))
+            user.ClientKeyData = base64.StdEncoding.EncodeToString([]byte(c.ClientKey))
+        }
+    }
+    if c.Exec != nil {
+        user.Exec = &kubeConfigExec{
+            ApiVersion: c.Exec.ApiVersion,
+            Command:    c.Exec.Command,
+            Args:       c.Exec.Args,
+        }
+        for name, value := range c.Exec.Env {
+            if !withCredentials {
+                value = "" // may carry secrets, e.g. cloud credentials
+            }
+            user.Exec.Env = append(user.Exec.Env, &kubeConfigExecEnvVar{Name: name, Value: value})
+        }
+        sort.Slice(user.Exec.Env, func(i, j int) bool { return user.Exec.Env[i].Name < user.Exec.Env[j].Name }) // stable output
+    } else if c.Eks != nil { // the built-in EKS token generation is not available outside of the provider
+        args := []string{"eks", "get-token", "--cluster-name", c.Eks.ClusterName}
+        if c.Eks.Region != "" {
+            args = append(args, "--region", c.Eks.Region)
+        }
+        if c.Eks.RoleArn != "" {
+            args = append(args, "--role-arn", c.Eks.RoleArn)
+        }
+        user.Exec = &kubeConfigExec{
+            ApiVersion: DefaultExecApiVersion,
+            Command:    "aws",
+            Args:       args,
+        }
+    }
+    if c.Impersonate != nil {
+        user.As = c.Impersonate.User
+        user.AsGroups = c.Impersonate.Groups
+        user.AsUserExtra = c.Impersonate.Extra
+    }
+
+    config := &kubeConfig{
+        ApiVersion:     "v1",
+        Kind:           "Config",
+        CurrentContext: name,
+        Clusters:       []*kubeConfigNamedCluster{{Name: name, Cluster: cluster}},
+        Users:          []*kubeConfigNamedUser{{Name: name, User: user}},
+        Contexts: []*kubeConfigNamedContext{{
+            Name: name,
+            Context: kubeConfigContext{
+                Cluster:   name,
+                User:      name,
+                Namespace: c.Namespace,
+            },
+        }},
+    }
+
+    encodedConfig, err := yaml.Marshal(config)
+    return string(encodedConfig), err
+}
+
+func loadInClusterConfig() (*Cluster, bool, error) {
+    host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+    if host == "" || port == "" {
+        return nil, false, nil
+    }
+
+    if _, err := os.Stat(inClusterTokenFile); err != nil {
+        return nil, false, nil // not a pod with a service account
+    }
+
+    caCert, err := ioutil.ReadFile(inClusterCaCertFile)
+    if err != nil {
+        return nil, true, fmt.Errorf("Failed to load in-cluster config: %v", err)
+    }
+
+    namespace, err := ioutil.ReadFile(inClusterNamespaceFile)
+    if err != nil && !os.IsNotExist(err) {
+        return nil, true, fmt.Errorf("Failed to load in-cluster config: %v", err)
+    }
+
+    if strings.Contains(host, ":") { // IPv6
+        host = fmt.Sprintf("[%s]", host)
+    }
+
+    return &Cluster{
+        ApiServer: fmt.Sprintf("https://%s:%s", host, port),
+        CaCert:    string(caCert),
+        TokenFile: inClusterTokenFile,
+        Namespace: strings.TrimSpace(string(namespace)),
+    }, true, nil
+}
+
+func dataOrFile(data, file, baseDir string) (string, error) {
+    if data != "" {
+        decoded, err := base64.StdEncoding.DecodeString(data)
+        if err != nil {
+            return "", fmt.Errorf("Invalid base64 data in kubeconfig: %v", err)
+        }
+        return string(decoded), nil
+    }
+
+    if file == "" {
+        return "", nil
+    }
+
+    contents, err := ioutil.ReadFile(resolvePath(file, baseDir))
+    if err != nil {
+        return "", fmt.Errorf("Failed to read file referenced in kubeconfig: %v", err)
+    }
+
+    return string(contents), nil
+}
+
+func resolvePath(path, baseDir string) string {
+    if path == "" || filepath.IsAbs(path) {
+        return path
+    }
+    return filepath.Join(baseDir, path)
+}
@@ -0,0 +1,226 @@
+package kubernetes_cluster
+
+import (
+    "encoding/base64"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+const testKubeConfig = `
+apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev-cluster
+  cluster:
+    server: https://dev.example.com
+    certificate-authority-data: ZGV2LWNh
+- name: prod-cluster
+  cluster:
+    server: https://prod.example.com
+    certificate-authority: certs/ca.crt
+    tls-server-name: kubernetes
+users:
+- name: dev-user
+  user:
+    token: dev-token
+- name: prod-user
+  user:
+    client-certificate: certs/client.crt
+    client-key-data: Y2xpZW50LWtleQ==
+    as: admin
+    exec:
+      command: ./bin/plugin
+      env:
+      - name: PROFILE
+        value: prod
+contexts:
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: dev-user
+    namespace: dev
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: prod-user
+`
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+    dir, err := ioutil.TempDir("", "kubeconfig")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for name, contents := range files {
+        path := filepath.Join(dir, name)
+        if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+            t.Fatal(err)
+        }
+        if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    return dir
+}
+
+func TestLoadKubeConfig(t *testing.T) {
+    dir := writeTestFiles(t, map[string]string{
+        "config":           testKubeConfig,
+        "certs/ca.crt":     "prod-ca",
+        "certs/client.crt": "client-cert",
+    })
+    defer os.RemoveAll(dir)
+
+    path := filepath.Join(dir, "config")
+
+    dev, err := loadKubeConfig(path, "", "", "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if dev.ApiServer != "https://dev.example.com" || dev.CaCert != "dev-ca" || dev.Token != "dev-token" || dev.Namespace != "dev" {
+        t.Errorf("current context is loaded incorrectly: %+v", dev)
+    }
+
+    prod, err := loadKubeConfig(path, "prod", "", "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if prod.ApiServer != "https://prod.example.com" || prod.CaCert != "prod-ca" || prod.TlsServerName != "kubernetes" {
+        t.Errorf("cluster of the \"prod\" context is loaded incorrectly: %+v", prod)
+    }
+    if prod.ClientCert != "client-cert" || prod.ClientKey != "client-key" || prod.Impersonate == nil || prod.Impersonate.User != "admin" {
+        t.Errorf("user of the \"prod\" context is loaded incorrectly: %+v", prod)
+    }
+    if prod.Exec == nil || prod.Exec.Command != filepath.Join(dir, "bin/plugin") || prod.Exec.Env["PROFILE"] != "prod" || prod.Exec.ApiVersion != DefaultExecApiVersion {
+        t.Errorf("exec plugin is loaded incorrectly: %+v", prod.Exec)
+    }
+
+    mixed, err := loadKubeConfig(path, "dev", "prod-cluster", "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if mixed.ApiServer != "https://prod.example.com" || mixed.Token != "dev-token" {
+        t.Errorf("context cluster is not overridden: %+v", mixed)
+    }
+
+    for _, names := range [][]string{{"staging", "", ""}, {"dev", "staging-cluster", ""}, {"dev", "", "staging-user"}} {
+        if _, err := loadKubeConfig(path, names[0], names[1], names[2]); err == nil {
+            t.Errorf("%q must not be found", names)
+        }
+    }
+
+    if _, err := loadKubeConfig(filepath.Join(dir, "missing"), "", "", ""); err == nil {
+        t.Error("missing kubeconfig must fail")
+    }
+}
+
+func TestLoadInClusterConfig(t *testing.T) {
+    dir := writeTestFiles(t, map[string]string{
+        "token":     "sa-token",
+        "ca.crt":    "sa-ca",
+        "namespace": "apps\n",
+    })
+    defer os.RemoveAll(dir)
+
+    defer func(tokenFile, caCertFile, namespaceFile string) {
+        inClusterTokenFile, inClusterCaCertFile, inClusterNamespaceFile = tokenFile, caCertFile, namespaceFile
+    }(inClusterTokenFile, inClusterCaCertFile, inClusterNamespaceFile)
+
+    inClusterTokenFile = filepath.Join(dir, "token")
+    inClusterCaCertFile = filepath.Join(dir, "ca.crt")
+    inClusterNamespaceFile = filepath.Join(dir, "namespace")
+
+    defer os.Setenv("KUBERNETES_SERVICE_HOST", os.Getenv("KUBERNETES_SERVICE_HOST"))
+    defer os.Setenv("KUBERNETES_SERVICE_PORT", os.Getenv("KUBERNETES_SERVICE_PORT"))
+
+    os.Setenv("KUBERNETES_SERVICE_HOST", "")
+    if _, inCluster, err := loadInClusterConfig(); inCluster || err != nil {
+        t.Errorf("not in a pod, got %v, %v", inCluster, err)
+    }
+
+    os.Setenv("KUBERNETES_SERVICE_HOST", "fd00::1")
+    os.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+    cluster, inCluster, err := loadInClusterConfig()
+    if !inCluster || err != nil {
+        t.Fatalf("in a pod, got %v, %v", inCluster, err)
+    }
+    if cluster.ApiServer != "https://[fd00::1]:443" || cluster.CaCert != "sa-ca" || cluster.TokenFile != inClusterTokenFile || cluster.Namespace != "apps" {
+        t.Errorf("in-cluster config is loaded incorrectly: %+v", cluster)
+    }
+
+    os.Remove(inClusterCaCertFile)
+    if _, inCluster, err := loadInClusterConfig(); !inCluster || err == nil {
+        t.Errorf("missing CA certificate must fail, got %v, %v", inCluster, err)
+    }
+
+    os.Remove(inClusterTokenFile)
+    if _, inCluster, err := loadInClusterConfig(); inCluster || err != nil {
+        t.Errorf("no service account token, got %v, %v", inCluster, err)
+    }
+}
+
+func TestKubeConfigRoundTrip(t *testing.T) {
+    cluster := &Cluster{
+        ApiServer:  "https://prod.example.com",
+        CaCert:     "prod-ca",
+        ClientCert: "client-cert",
+        ClientKey:  "client-key",
+        Namespace:  "apps",
+        Exec:       &ExecConfig{ApiVersion: DefaultExecApiVersion, Command: "plugin", Env: map[string]string{"B": "2", "A": "1"}},
+    }
+
+    kubeConfig, err := cluster.KubeConfig("terraform", true)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    dir := writeTestFiles(t, map[string]string{"config": kubeConfig})
+    defer os.RemoveAll(dir)
+
+    loaded, err := loadKubeConfig(filepath.Join(dir, "config"), "terraform", "", "")
+    if err != nil {
+        t.Fatalf("rendered kubeconfig is not loaded: %v\n%s", err, kubeConfig)
+    }
+
+    if loaded.ApiServer != cluster.ApiServer || loaded.CaCert != cluster.CaCert || loaded.ClientCert != cluster.ClientCert || loaded.ClientKey != cluster.ClientKey {
+        t.Errorf("server or credentials are lost: %+v", loaded)
+    }
+    if loaded.Namespace != "apps" {
+        t.Errorf("context namespace is \"%s\", expected \"apps\"", loaded.Namespace)
+    }
+    if loaded.Exec == nil || loaded.Exec.Command != "plugin" || len(loaded.Exec.Env) != 2 {
+        t.Errorf("exec plugin is lost: %+v", loaded.Exec)
+    }
+}
+
+func TestKubeConfigWithoutCredentials(t *testing.T) {
+    cluster := &Cluster{
+        ApiServer:  "https://prod.example.com",
+        ClientCert: "client-cert",
+        ClientKey:  "client-key",
+        Token:      "secret-token",
+        Password:   "secret-password",
+        Exec:       &ExecConfig{ApiVersion: DefaultExecApiVersion, Command: "plugin", Env: map[string]string{"AWS_SECRET_ACCESS_KEY": "secret-key"}},
+    }
+
+    kubeConfig, err := cluster.KubeConfig("terraform", false)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for _, secret := range []string{"client-key", "secret-token", "secret-password", "secret-key"} {
+        if strings.Contains(kubeConfig, secret) || strings.Contains(kubeConfig, base64.StdEncoding.EncodeToString([]byte(secret))) {
+            t.Errorf("kubeconfig contains \"%s\":\n%s", secret, kubeConfig)
+        }
+    }
+
+    if !strings.Contains(kubeConfig, "https://prod.example.com") || !strings.Contains(kubeConfig, "AWS_SECRET_ACCESS_KEY") {
+        t.Errorf("kubeconfig lost the server or the exec plugin:\n%s", kubeConfig)
+    }
+}
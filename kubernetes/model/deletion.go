@@ -0,0 +1,131 @@
+package kubernetes_model
+
+import (
+    "encoding/json"
+    "fmt"
+    "github.com/hashicorp/terraform/helper/schema"
+    "time"
+)
+
+const DefaultForceFinalizeGracePeriod = "60s"
+
+const (
+    PropagationBackground = "Background"
+    PropagationForeground = "Foreground"
+    PropagationOrphan     = "Orphan"
+)
+
+const (
+    DestroyDelete  = "delete"
+    DestroyAbandon = "abandon"
+    DestroyRefuse  = "refuse"
+)
+
+type KubeDeleteOptions struct {
+    Propagation              string
+    PreventDestroy           bool
+    Abandon                  bool
+    ForceFinalize            bool
+    ForceFinalizeGracePeriod time.Duration
+}
+
+type DestroyOutcome struct { // what destroy does with the object
+    Action string
+    Reason string
+}
+
+type deleteOptionsBody struct {
+    ApiVersion        string `json:"apiVersion"`
+    Kind              string `json:"kind"`
+    PropagationPolicy string `json:"propagationPolicy,omitempty"`
+}
+
+type terminatingObject struct {
+    Metadata struct {
+        DeletionTimestamp string   `json:"deletionTimestamp"`
+        Finalizers        []string `json:"finalizers"`
+    } `json:"metadata"`
+    Spec struct {
+        Finalizers []string `json:"finalizers"` // namespaces
+    } `json:"spec"`
+}
+
+func ParseDeleteOptions(resourceData *schema.ResourceData) *KubeDeleteOptions {
+    options := &KubeDeleteOptions{
+        Propagation:    resourceData.Get("deletion_propagation").(string),
+        PreventDestroy: resourceData.Get("prevent_destroy_in_cluster").(bool),
+        Abandon:        resourceData.Get("abandon_on_destroy").(bool),
+        ForceFinalize:  resourceData.Get("force_finalize").(bool),
+    }
+
+    if gracePeriod, err := time.ParseDuration(resourceData.Get("force_finalize_grace_period").(string)); err == nil {
+        options.ForceFinalizeGracePeriod = gracePeriod
+    }
+
+    return options
+}
+
+func (options *KubeDeleteOptions) Outcome(resourcePath *KubeResourcePath, protection *ProtectedObjects, liveContents []byte) (*DestroyOutcome, error) {
+    if options.Abandon {
+        return &DestroyOutcome{Action: DestroyAbandon, Reason: "\"abandon_on_destroy\" is set"}, nil
+    }
+
+    if options.PreventDestroy {
+        return &DestroyOutcome{Action: DestroyRefuse, Reason: "\"prevent_destroy_in_cluster\" is set"}, nil
+    }
+
+    reason, err := protection.Match(resourcePath, liveContents)
+    if err != nil {
+        return nil, err
+    }
+
+    if reason != "" {
+        if protection.IsAbandon() {
+            return &DestroyOutcome{Action: DestroyAbandon, Reason: reason}, nil
+        }
+        return &DestroyOutcome{Action: DestroyRefuse, Reason: reason}, nil
+    }
+
+    if options.ForceFinalize {
+        return &DestroyOutcome{Action: DestroyDelete, Reason: fmt.Sprintf("finalizers are removed forcibly after %v", options.ForceFinalizeGracePeriod)}, nil
+    }
+
+    return &DestroyOutcome{Action: DestroyDelete}, nil
+}
+
+func (outcome *DestroyOutcome) String() string {
+    if outcome.Reason == "" {
+        return outcome.Action
+    }
+    return fmt.Sprintf("%s: %s", outcome.Action, outcome.Reason)
+}
+
+func (options *KubeDeleteOptions) Body() ([]byte, error) {
+    return json.Marshal(&deleteOptionsBody{
+        ApiVersion:        "v1",
+        Kind:              "DeleteOptions",
+        PropagationPolicy: options.Propagation,
+    })
+}
+
+func Finalizers(liveContents []byte) ([]string, error) {
+    object := &terminatingObject{}
+    if err := json.Unmarshal(liveContents, object); err != nil {
+        return nil, err
+    }
+
+    return append(object.Metadata.Finalizers, object.Spec.Finalizers...), nil
+}
+
+func WithoutSpecFinalizers(liveContents []byte) ([]byte, error) { // for the "finalize" subresource of namespaces
+    var object map[string]interface{}
+    if err := json.Unmarshal(liveContents, &object); err != nil {
+        return nil, err
+    }
+
+    if spec, ok := object["spec"].(map[string]interface{}); ok {
+        spec["finalizers"] = []string{}
+    }
+
+    return json.Marshal(object)
+}
@@ -0,0 +1,37 @@
+package kubernetes_model
+
+import (
+    "testing"
+    "time"
+)
+
+func TestDestroyOutcome(t *testing.T) {
+    pod := ParsePath("api/v1/namespaces/default/pods/web")
+    defaultNamespace := ParsePath("api/v1/namespaces/default")
+    labeledPod := []byte(`{"kind": "Pod", "metadata": {"labels": {"tier": "db"}}}`)
+
+    refusing := &ProtectedObjects{LabelSelectors: []string{"tier=db"}, OnDestroy: ProtectionRefuse}
+
+    outcomes := []struct {
+        path       *KubeResourcePath
+        options    *KubeDeleteOptions
+        protection *ProtectedObjects
+        live       []byte
+        expected   string
+    }{
+        {pod, &KubeDeleteOptions{}, DefaultProtectedObjects, nil, "delete"},
+        {pod, &KubeDeleteOptions{Abandon: true}, DefaultProtectedObjects, nil, "abandon: \"abandon_on_destroy\" is set"},
+        {pod, &KubeDeleteOptions{PreventDestroy: true}, DefaultProtectedObjects, nil, "refuse: \"prevent_destroy_in_cluster\" is set"},
+        {defaultNamespace, &KubeDeleteOptions{}, DefaultProtectedObjects, nil, "abandon: namespace \"default\" is protected"},
+        {pod, &KubeDeleteOptions{}, refusing, labeledPod, "refuse: labels match protected selector \"tier=db\""},
+        {pod, &KubeDeleteOptions{ForceFinalize: true, ForceFinalizeGracePeriod: time.Minute}, refusing, nil, "delete: finalizers are removed forcibly after 1m0s"},
+    }
+
+    for _, outcome := range outcomes {
+        if actual, err := outcome.options.Outcome(outcome.path, outcome.protection, outcome.live); err != nil {
+            t.Errorf("%s: %v", outcome.expected, err)
+        } else if actual.String() != outcome.expected {
+            t.Errorf("got \"%s\", expected \"%s\"", actual, outcome.expected)
+        }
+    }
+}
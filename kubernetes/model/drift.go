@@ -0,0 +1,153 @@
+package kubernetes_model
+
+import (
+    "fmt"
+    "reflect"
+    "sort"
+    "strings"
+)
+
+func Drift(resource *KubeResource, liveContents []byte) ([]string, []byte, error) {
+    declared, err := Decode(resource.Contents, resource.Encoding)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    live, err := Decode(liveContents, EncodingJson)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    driftedFields := make([]string, 0)
+    projection := project(declared, live, "", &driftedFields)
+
+    if len(driftedFields) == 0 {
+        return driftedFields, resource.Contents, nil
+    }
+
+    sort.Strings(driftedFields)
+
+    projectedContents, err := Encode(projection, resource.Encoding)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    return driftedFields, projectedContents, nil
+}
+
+var quantityFields = map[string]bool{ // the server normalizes quantities under these fields, e.g. cpu 0.5 vs "500m", "1024Mi" vs "1Gi"
+    "limits":      true,
+    "requests":    true,
+    "capacity":    true,
+    "allocatable": true,
+    "hard":        true,
+    "storage":     true,
+}
+
+var driftOnlyMergeKeys = map[string]string{ // lists which are replaced on update, but extended by admission controllers
+    "tolerations": "key",
+}
+
+var writeOnlyFields = map[string]bool{ // top-level fields which the server folds into other fields and never returns
+    "stringData": true,
+}
+
+func project(declared, live interface{}, path string, driftedFields *[]string) interface{} {
+    return projectValue(declared, live, path, "", false, driftedFields)
+}
+
+func projectValue(declared, live interface{}, path, field string, quantity bool, driftedFields *[]string) interface{} {
+    switch declaredValue := declared.(type) {
+    case map[string]interface{}:
+        liveValue, ok := live.(map[string]interface{})
+        if !ok {
+            *driftedFields = append(*driftedFields, fieldPath(path))
+            return live
+        }
+
+        result := make(map[string]interface{}, len(declaredValue))
+        for key, item := range declaredValue {
+            itemPath := fmt.Sprintf("%s.%s", path, key)
+            if path == "" && writeOnlyFields[key] {
+                result[key] = item
+            } else if liveItem, exists := liveValue[key]; exists {
+                result[key] = projectValue(item, liveItem, itemPath, key, quantity || quantityFields[key], driftedFields)
+            } else if item != nil {
+                *driftedFields = append(*driftedFields, fieldPath(itemPath))
+            }
+        }
+        return result
+
+    case []interface{}:
+        liveValue, ok := live.([]interface{})
+        if !ok {
+            *driftedFields = append(*driftedFields, fieldPath(path))
+            return live
+        }
+
+        if mergeKey := driftMergeKey(field, declaredValue); mergeKey != "" {
+            return projectKeyedList(declaredValue, liveValue, path, mergeKey, quantity, driftedFields)
+        }
+
+        if len(liveValue) != len(declaredValue) {
+            *driftedFields = append(*driftedFields, fieldPath(path))
+            return live
+        }
+
+        result := make([]interface{}, len(declaredValue))
+        for i, item := range declaredValue {
+            result[i] = projectValue(item, liveValue[i], fmt.Sprintf("%s[%d]", path, i), "", quantity, driftedFields)
+        }
+        return result
+
+    default:
+        if !scalarsEqual(declared, live, quantity) {
+            *driftedFields = append(*driftedFields, fieldPath(path))
+            return live
+        }
+        return declared
+    }
+}
+
+func driftMergeKey(field string, items []interface{}) string {
+    if mergeKey, ok := driftOnlyMergeKeys[field]; ok && len(items) != 0 && allHaveKey(items, mergeKey) {
+        return mergeKey
+    }
+    return listMergeKey(field, items)
+}
+
+// Items are matched by the merge key, so items added by the server or admission controllers (e.g. injected sidecars,
+// volumes and tolerations) are not a drift, while declared items missing in the live object are
+func projectKeyedList(declared, live []interface{}, path, mergeKey string, quantity bool, driftedFields *[]string) []interface{} {
+    result := make([]interface{}, 0, len(declared))
+    for i, item := range declared {
+        itemPath := fmt.Sprintf("%s[%d]", path, i)
+        if liveItem := findListItem(live, mergeKey, item.(map[string]interface{})[mergeKey]); liveItem != nil {
+            result = append(result, projectValue(item, liveItem, itemPath, "", quantity, driftedFields))
+        } else {
+            *driftedFields = append(*driftedFields, fieldPath(itemPath))
+        }
+    }
+    return result
+}
+
+func scalarsEqual(declared, live interface{}, quantity bool) bool {
+    if reflect.DeepEqual(declared, live) {
+        return true
+    }
+    if declared == nil || live == nil {
+        return false
+    }
+    declaredString, liveString := fmt.Sprintf("%v", declared), fmt.Sprintf("%v", live)
+    if declaredString == liveString { // e.g. port "8080" vs 8080
+        return true
+    }
+    return quantity && quantitiesEqual(declaredString, liveString)
+}
+
+func fieldPath(path string) string {
+    if path == "" {
+        return "."
+    }
+    return strings.TrimPrefix(path, ".")
+}
@@ -0,0 +1,123 @@
+package kubernetes_model
+
+import (
+    "reflect"
+    "testing"
+)
+
+func checkDrift(t *testing.T, declared, live string, expectedFields []string, expectedContents string) {
+    resource := &KubeResource{
+        KubeResourceOptions: &KubeResourceOptions{Encoding: EncodingJson},
+        Contents:            []byte(declared),
+    }
+
+    driftedFields, contents, err := Drift(resource, []byte(live))
+    if err != nil {
+        t.Fatalf("Drift(%s) failed: %v", declared, err)
+    }
+
+    if !reflect.DeepEqual(driftedFields, expectedFields) {
+        t.Errorf("drifted fields of %s: got %q, expected %q", declared, driftedFields, expectedFields)
+    }
+
+    if !jsonEqual(t, contents, []byte(expectedContents)) {
+        t.Errorf("contents projected from %s: got %s, expected %s", live, contents, expectedContents)
+    }
+}
+
+func TestDriftIgnoresServerPopulatedFields(t *testing.T) {
+    declared := `{"metadata": {"name": "web"}, "spec": {"replicas": 2, "port": "8080"}}`
+    live := `{"metadata": {"name": "web", "uid": "42"}, "spec": {"replicas": 2, "port": 8080, "paused": false}, "status": {}}`
+
+    checkDrift(t, declared, live, []string{}, declared)
+}
+
+func TestDriftOfChangedAndRemovedFields(t *testing.T) {
+    checkDrift(t,
+        `{"metadata": {"labels": {"app": "web", "tier": "front"}}, "spec": {"replicas": 2}}`,
+        `{"metadata": {"labels": {"app": "web"}}, "spec": {"replicas": 3}}`,
+        []string{"metadata.labels.tier", "spec.replicas"},
+        `{"metadata": {"labels": {"app": "web"}}, "spec": {"replicas": 3}}`,
+    )
+}
+
+func TestDriftInLists(t *testing.T) {
+    checkDrift(t,
+        `{"spec": {"containers": [{"name": "web", "image": "nginx:1"}]}}`,
+        `{"spec": {"containers": [{"name": "web", "image": "nginx:2", "imagePullPolicy": "IfNotPresent"}]}}`,
+        []string{"spec.containers[0].image"},
+        `{"spec": {"containers": [{"name": "web", "image": "nginx:2"}]}}`,
+    )
+
+    checkDrift(t,
+        `{"spec": {"args": ["a"]}}`,
+        `{"spec": {"args": ["a", "b"]}}`,
+        []string{"spec.args"},
+        `{"spec": {"args": ["a", "b"]}}`,
+    )
+}
+
+func TestDriftInKeyedLists(t *testing.T) {
+    declared := `{"spec": {
+        "containers": [{"name": "web", "volumeMounts": [{"mountPath": "/data", "name": "data"}]}],
+        "volumes": [{"name": "data", "emptyDir": {}}],
+        "tolerations": [{"key": "dedicated", "operator": "Exists"}]
+    }}`
+
+    live := `{"spec": {
+        "containers": [
+            {"name": "istio-proxy", "image": "proxy"},
+            {"name": "web", "volumeMounts": [{"mountPath": "/var/run/secrets/token", "name": "token"}, {"mountPath": "/data", "name": "data"}]}
+        ],
+        "volumes": [{"name": "token", "secret": {}}, {"name": "data", "emptyDir": {}}],
+        "tolerations": [{"key": "node.kubernetes.io/not-ready", "operator": "Exists"}, {"key": "dedicated", "operator": "Exists"}]
+    }}`
+
+    checkDrift(t, declared, live, []string{}, declared) // injected items are not a drift
+
+    checkDrift(t,
+        `{"spec": {"containers": [{"name": "web", "image": "nginx"}, {"name": "log", "image": "fluentd"}]}}`,
+        `{"spec": {"containers": [{"name": "web", "image": "nginx"}]}}`,
+        []string{"spec.containers[1]"},
+        `{"spec": {"containers": [{"name": "web", "image": "nginx"}]}}`,
+    )
+}
+
+func TestDriftIgnoresWriteOnlyFields(t *testing.T) {
+    declared := `{"kind": "Secret", "stringData": {"password": "secret"}}`
+    checkDrift(t, declared, `{"kind": "Secret", "data": {"password": "c2VjcmV0"}}`, []string{}, declared)
+}
+
+func TestDriftOfNormalizedQuantities(t *testing.T) {
+    declared := `{"resources": {"limits": {"cpu": 0.5, "memory": "1024Mi"}, "requests": {"storage": "1e3", "ephemeral-storage": "2000m"}}}`
+    live := `{"resources": {"limits": {"cpu": "500m", "memory": "1Gi"}, "requests": {"storage": "1k", "ephemeral-storage": "2"}}}`
+    checkDrift(t, declared, live, []string{}, declared)
+
+    checkDrift(t, // only really changed quantities are taken from the server
+        `{"spec": {"hard": {"cpu": 0.5, "memory": "1Mi"}}}`,
+        `{"spec": {"hard": {"cpu": "500m", "memory": "1M"}}}`,
+        []string{"spec.hard.memory"},
+        `{"spec": {"hard": {"cpu": 0.5, "memory": "1M"}}}`,
+    )
+
+    checkDrift(t, // other fields are compared as is
+        `{"metadata": {"labels": {"version": "1.10"}}}`,
+        `{"metadata": {"labels": {"version": "1.1"}}}`,
+        []string{"metadata.labels.version"},
+        `{"metadata": {"labels": {"version": "1.1"}}}`,
+    )
+}
+
+func jsonEqual(t *testing.T, actual, expected []byte) bool {
+    actualValue, err := Decode(actual, EncodingJson)
+    if err != nil {
+        t.Fatalf("invalid JSON %s: %v", actual, err)
+    }
+
+    expectedValue, err := Decode(expected, EncodingJson)
+    if err != nil {
+        t.Fatalf("invalid JSON %s: %v", expected, err)
+    }
+
+    return reflect.DeepEqual(actualValue, expectedValue)
+}
@@ -0,0 +1,73 @@
+package kubernetes_model
+
+import (
+    "encoding/json"
+    "fmt"
+    "gopkg.in/yaml.v2"
+)
+
+func Decode(contents []byte, encoding string) (interface{}, error) { // produces JSON-compatible values for both encodings
+    var value interface{}
+
+    if encoding == EncodingJson {
+        if err := json.Unmarshal(contents, &value); err != nil {
+            return nil, err
+        }
+        return value, nil
+    }
+
+    if err := yaml.Unmarshal(contents, &value); err != nil {
+        return nil, err
+    }
+
+    jsonContents, err := json.Marshal(jsonCompatible(value))
+    if err != nil {
+        return nil, err
+    }
+
+    value = nil
+    if err := json.Unmarshal(jsonContents, &value); err != nil {
+        return nil, err
+    }
+
+    return value, nil
+}
+
+func ToJson(contents []byte, encoding string) ([]byte, error) {
+    if encoding == EncodingJson {
+        return contents, nil
+    }
+
+    value, err := Decode(contents, encoding)
+    if err != nil {
+        return nil, err
+    }
+
+    return json.Marshal(value)
+}
+
+func Encode(value interface{}, encoding string) ([]byte, error) {
+    if encoding == EncodingJson {
+        return json.MarshalIndent(value, "", "  ")
+    }
+    return yaml.Marshal(value)
+}
+
+func jsonCompatible(value interface{}) interface{} {
+    switch typedValue := value.(type) {
+    case map[interface{}]interface{}:
+        result := make(map[string]interface{}, len(typedValue))
+        for key, item := range typedValue {
+            result[fmt.Sprintf("%v", key)] = jsonCompatible(item)
+        }
+        return result
+    case []interface{}:
+        result := make([]interface{}, len(typedValue))
+        for i, item := range typedValue {
+            result[i] = jsonCompatible(item)
+        }
+        return result
+    default:
+        return value
+    }
+}
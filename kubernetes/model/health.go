@@ -0,0 +1,60 @@
+package kubernetes_model
+
+import (
+    "encoding/json"
+    "github.com/hashicorp/terraform/helper/schema"
+    "strings"
+)
+
+const SystemNamespace = "kube-system"
+
+type HealthGate struct {
+    Readyz            bool
+    MinReadyNodes     int
+    SystemDeployments []string
+}
+
+type nodeList struct {
+    Items []k8sObject
+}
+
+func ParseHealthGate(clusterData *schema.ResourceData) *HealthGate {
+    gate := &HealthGate{
+        Readyz:        clusterData.Get("wait_for_readyz").(bool),
+        MinReadyNodes: clusterData.Get("min_ready_nodes").(int),
+    }
+
+    for _, rawName := range clusterData.Get("wait_for_system_deployments").([]interface{}) {
+        if name, ok := rawName.(string); ok && name != "" {
+            gate.SystemDeployments = append(gate.SystemDeployments, name)
+        }
+    }
+
+    return gate
+}
+
+func FailedHealthChecks(verboseOutput []byte) []string { // "[+]etcd ok", "[-]etcd failed: reason withheld"
+    failed := make([]string, 0)
+    for _, line := range strings.Split(string(verboseOutput), "\n") {
+        if line = strings.TrimSpace(line); strings.HasPrefix(line, "[-]") {
+            failed = append(failed, strings.TrimPrefix(line, "[-]"))
+        }
+    }
+    return failed
+}
+
+func CountReadyNodes(nodeListContents []byte) (int, int, error) {
+    nodes := &nodeList{}
+    if err := json.Unmarshal(nodeListContents, nodes); err != nil {
+        return 0, 0, err
+    }
+
+    ready := 0
+    for i := range nodes.Items {
+        if nodes.Items[i].hasCondition("Ready") {
+            ready++
+        }
+    }
+
+    return ready, len(nodes.Items), nil
+}
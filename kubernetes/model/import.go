@@ -0,0 +1,109 @@
+package kubernetes_model
+
+import (
+    "errors"
+    "fmt"
+    "regexp"
+    "strings"
+)
+
+var apiVersionPattern = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+
+var serverPopulatedMetadata = []string{
+    "uid",
+    "resourceVersion",
+    "generation",
+    "creationTimestamp",
+    "deletionTimestamp",
+    "deletionGracePeriodSeconds",
+    "selfLink",
+    "managedFields",
+}
+
+func ParseImportId(id string) (*KubeResourcePath, error) {
+    id = strings.Trim(id, "/")
+    if strings.HasPrefix(id, "api/") || strings.HasPrefix(id, "apis/") {
+        return parseImportPath(strings.SplitN(id, "/", 2)[1])
+    }
+
+    if parts := strings.Split(id, "/"); len(parts) > 3 || (len(parts) == 3 && apiVersionPattern.MatchString(parts[0])) {
+        return parseImportPath(id)
+    }
+
+    return nil, nil // "[<namespace>/]<kind>/<name>", kind must be resolved via API discovery
+}
+
+func SplitImportId(id string) (string, string, string, error) {
+    parts := strings.Split(strings.Trim(id, "/"), "/")
+
+    switch len(parts) {
+    case 2:
+        return "", parts[0], parts[1], nil
+    case 3:
+        return parts[0], parts[1], parts[2], nil
+    }
+
+    return "", "", "", fmt.Errorf("Invalid import ID: %s; expected \"[<namespace>/]<kind>/<name>\" or API path like \"apps/v1/namespaces/<namespace>/deployments/<name>\"", id)
+}
+
+func ImportContents(liveContents []byte, encoding string) ([]byte, error) {
+    value, err := Decode(liveContents, EncodingJson)
+    if err != nil {
+        return nil, err
+    }
+
+    object, ok := value.(map[string]interface{})
+    if !ok {
+        return nil, errors.New("Resource contents must be an object")
+    }
+
+    delete(object, "status")
+
+    if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+        for _, field := range serverPopulatedMetadata {
+            delete(metadata, field)
+        }
+
+        if annotations := getAnnotations(object, false); annotations != nil {
+            delete(annotations, LastAppliedConfigAnnotation)
+            if len(annotations) == 0 {
+                delete(metadata, "annotations")
+            }
+        }
+    }
+
+    return Encode(object, encoding)
+}
+
+func parseImportPath(path string) (*KubeResourcePath, error) {
+    parts := strings.Split(path, "/")
+
+    apiPathLength := 1 // "v1"
+    if len(parts) != 3 && len(parts) != 5 {
+        apiPathLength = 2 // "apps/v1"
+    }
+
+    apiPath := fmt.Sprintf("api/%s", strings.Join(parts[:apiPathLength], "/"))
+    if apiPathLength == 2 {
+        apiPath = fmt.Sprintf("apis/%s", strings.Join(parts[:apiPathLength], "/"))
+    }
+
+    switch rest := parts[apiPathLength:]; {
+    case len(rest) == 2:
+        return &KubeResourcePath{
+            ApiPath:    apiPath,
+            Namespace:  "",
+            Collection: rest[0],
+            Name:       rest[1],
+        }, nil
+    case len(rest) == 4 && rest[0] == namespacesCollection:
+        return &KubeResourcePath{
+            ApiPath:    apiPath,
+            Namespace:  rest[1],
+            Collection: rest[2],
+            Name:       rest[3],
+        }, nil
+    }
+
+    return nil, fmt.Errorf("Invalid resource path: %s", path)
+}
@@ -0,0 +1,82 @@
+package kubernetes_model
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestParseImportPath(t *testing.T) {
+    paths := map[string]*KubeResourcePath{
+        "api/v1/namespaces/default/pods/web":              {ApiPath: "api/v1", Namespace: "default", Collection: "pods", Name: "web"},
+        "/apis/apps/v1/namespaces/prod/deployments/web/":  {ApiPath: "apis/apps/v1", Namespace: "prod", Collection: "deployments", Name: "web"},
+        "apps/v1/namespaces/prod/deployments/web":         {ApiPath: "apis/apps/v1", Namespace: "prod", Collection: "deployments", Name: "web"},
+        "v1/namespaces/prod/configmaps/settings":          {ApiPath: "api/v1", Namespace: "prod", Collection: "configmaps", Name: "settings"},
+        "v1/namespaces/prod":                              {ApiPath: "api/v1", Collection: "namespaces", Name: "prod"},
+        "rbac.authorization.k8s.io/v1/clusterroles/admin": {ApiPath: "apis/rbac.authorization.k8s.io/v1", Collection: "clusterroles", Name: "admin"},
+    }
+
+    for id, expected := range paths {
+        if path, err := ParseImportId(id); err != nil {
+            t.Errorf("ParseImportId(%s) failed: %v", id, err)
+        } else if !reflect.DeepEqual(path, expected) {
+            t.Errorf("ParseImportId(%s) = %+v, expected %+v", id, path, expected)
+        }
+    }
+
+    for _, id := range []string{"apps/v1/namespaces/prod/deployments", "apps/v1/pods/prod/deployments/web"} {
+        if path, err := ParseImportId(id); err == nil {
+            t.Errorf("ParseImportId(%s) = %+v, expected an error", id, path)
+        }
+    }
+}
+
+func TestParseImportIdOfKind(t *testing.T) {
+    for _, id := range []string{"prod/deployment/web", "deployment/web"} {
+        if path, err := ParseImportId(id); path != nil || err != nil {
+            t.Errorf("ParseImportId(%s) = %+v, %v; the kind must be left for API discovery", id, path, err)
+        }
+    }
+
+    namespace, kind, name, err := SplitImportId("/prod/deployment/web/")
+    if err != nil || namespace != "prod" || kind != "deployment" || name != "web" {
+        t.Errorf("SplitImportId: %s, %s, %s, %v", namespace, kind, name, err)
+    }
+
+    namespace, kind, name, err = SplitImportId("deployment/web")
+    if err != nil || namespace != "" || kind != "deployment" || name != "web" {
+        t.Errorf("SplitImportId without namespace: %s, %s, %s, %v", namespace, kind, name, err)
+    }
+
+    for _, id := range []string{"web", "a/b/c/d"} {
+        if _, _, _, err := SplitImportId(id); err == nil {
+            t.Errorf("SplitImportId(%s) must fail", id)
+        }
+    }
+}
+
+func TestImportContents(t *testing.T) {
+    live := `{
+        "apiVersion": "v1",
+        "kind": "ConfigMap",
+        "metadata": {
+            "name": "settings",
+            "uid": "42",
+            "resourceVersion": "7",
+            "creationTimestamp": "2020-01-01T00:00:00Z",
+            "managedFields": [],
+            "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+        },
+        "data": {"a": "1"},
+        "status": {}
+    }`
+
+    contents, err := ImportContents([]byte(live), EncodingJson)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    expected := `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings"}, "data": {"a": "1"}}`
+    if !jsonEqual(t, contents, []byte(expected)) {
+        t.Errorf("server-populated fields are not removed: %s", contents)
+    }
+}
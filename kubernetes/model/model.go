@@ -5,11 +5,18 @@ import (
 )
 
 const (
-    DefaultApiPath       = "api/v1"
-    DefaultNamespace     = "default"
-    EncodingJson         = "json"
-    EncodingYaml         = "yaml"
-    namespacesCollection = "namespaces"
+    ApplyModeClientSide     = "client-side"
+    ApplyModeServerSide     = "server-side"
+    DefaultApiPath          = "api/v1"
+    DefaultFieldManager     = "terraform"
+    DefaultNamespace        = "default"
+    EncodingJson            = "json"
+    EncodingYaml            = "yaml"
+    UpdateStrategyMerge     = "merge"
+    UpdateStrategyReplace   = "replace"
+    UpdateStrategyStrategic = "strategic"
+    UpdateStrategyThreeWay  = "three-way"
+    namespacesCollection    = "namespaces"
 )
 
 type KubeResourcePath struct {
@@ -19,11 +26,24 @@ type KubeResourcePath struct {
     Name       string
 }
 
+type KubeResourceOptions struct {
+    Encoding       string
+    ApplyMode      string
+    FieldManager   string
+    ForceConflicts bool
+    UpdateStrategy string
+    WaitForReady   bool
+}
+
 type KubeResource struct {
     *KubeResourcePath
+    *KubeResourceOptions
 
-    Contents []byte
-    Encoding string
+    ApiVersion string
+    Kind       string
+    Contents   []byte
+
+    declaredNamespace string
 }
 
 func (resourcePath *KubeResourcePath) IsGlobal() bool {
@@ -34,10 +54,6 @@ func (resourcePath *KubeResourcePath) IsNamespace() bool {
     return resourcePath.Collection == namespacesCollection
 }
 
-func (resourcePath *KubeResourcePath) CannotBeDeleted() bool {
-    return resourcePath.IsNamespace() && (resourcePath.Name == DefaultNamespace || resourcePath.Name == "kube-system" || resourcePath.Name == "kube-public")
-}
-
 func (resourcePath *KubeResourcePath) CollectionPath() string {
     if resourcePath.IsGlobal() {
         return fmt.Sprintf("%s/%s", resourcePath.ApiPath, resourcePath.Collection)
@@ -48,3 +64,29 @@ func (resourcePath *KubeResourcePath) CollectionPath() string {
 func (resourcePath *KubeResourcePath) Path() string {
     return fmt.Sprintf("%s/%s", resourcePath.CollectionPath(), resourcePath.Name)
 }
+
+func (resource *KubeResource) SetScope(collection string, namespaced bool) {
+    resource.Collection = collection
+
+    if !namespaced {
+        resource.Namespace = ""
+    } else if resource.declaredNamespace != "" {
+        resource.Namespace = resource.declaredNamespace
+    } else {
+        resource.Namespace = DefaultNamespace
+    }
+}
+
+func (resource *KubeResource) SetDefaultNamespace(namespace string) { // applies the default namespace of the kubeconfig context
+    if !resource.IsGlobal() && resource.declaredNamespace == "" {
+        resource.Namespace = namespace
+    }
+}
+
+func (options *KubeResourceOptions) IsServerSideApply() bool {
+    return options.ApplyMode == ApplyModeServerSide
+}
+
+func (options *KubeResourceOptions) IsThreeWayMerge() bool {
+    return !options.IsServerSideApply() && options.UpdateStrategy == UpdateStrategyThreeWay
+}
@@ -0,0 +1,243 @@
+package kubernetes_model
+
+import (
+    "encoding/json"
+    "errors"
+    "reflect"
+    "strings"
+)
+
+const LastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+var strategicMergeKeys = map[string]string{
+    "containers":          "name",
+    "initContainers":      "name",
+    "ephemeralContainers": "name",
+    "volumes":             "name",
+    "volumeMounts":        "mountPath",
+    "volumeDevices":       "devicePath",
+    "env":                 "name",
+    "imagePullSecrets":    "name",
+    "hostAliases":         "ip",
+    "ownerReferences":     "uid",
+    "ports":               "port",
+}
+
+var builtInApiGroups = map[string]bool{
+    "admissionregistration.k8s.io": true,
+    "apiextensions.k8s.io":         true,
+    "apiregistration.k8s.io":       true,
+    "apps":                         true,
+    "autoscaling":                  true,
+    "batch":                        true,
+    "certificates.k8s.io":          true,
+    "coordination.k8s.io":          true,
+    "discovery.k8s.io":             true,
+    "events.k8s.io":                true,
+    "extensions":                   true,
+    "flowcontrol.apiserver.k8s.io": true,
+    "networking.k8s.io":            true,
+    "node.k8s.io":                  true,
+    "policy":                       true,
+    "rbac.authorization.k8s.io":    true,
+    "scheduling.k8s.io":            true,
+    "storage.k8s.io":               true,
+}
+
+func WithLastAppliedConfig(contents []byte, encoding string) ([]byte, error) {
+    value, err := Decode(contents, encoding)
+    if err != nil {
+        return nil, err
+    }
+
+    object, ok := value.(map[string]interface{})
+    if !ok {
+        return nil, errors.New("Resource contents must be an object")
+    }
+
+    annotations := getAnnotations(object, true)
+    delete(annotations, LastAppliedConfigAnnotation) // kubectl stores the configuration without the annotation itself
+    if len(annotations) == 0 {
+        delete(object["metadata"].(map[string]interface{}), "annotations")
+    }
+
+    lastAppliedConfig, err := json.Marshal(object)
+    if err != nil {
+        return nil, err
+    }
+
+    getAnnotations(object, true)[LastAppliedConfigAnnotation] = string(lastAppliedConfig)
+
+    return json.Marshal(object)
+}
+
+func ThreeWayMergePatch(desiredContents, liveContents []byte) ([]byte, bool, error) { // the flag tells whether the patch is a strategic merge patch
+    var desired, live map[string]interface{}
+
+    if err := json.Unmarshal(desiredContents, &desired); err != nil {
+        return nil, false, err
+    }
+
+    if err := json.Unmarshal(liveContents, &live); err != nil {
+        return nil, false, err
+    }
+
+    var lastApplied map[string]interface{}
+    if lastAppliedConfig, ok := getAnnotations(live, false)[LastAppliedConfigAnnotation].(string); ok {
+        if err := json.Unmarshal([]byte(lastAppliedConfig), &lastApplied); err != nil {
+            lastApplied = nil // broken annotation, nothing is removed then
+        }
+    }
+
+    apiVersion, _ := desired["apiVersion"].(string)
+    strategic := isBuiltInApiVersion(apiVersion) // custom resources do not support strategic merge patch
+
+    patch := threeWayMerge(lastApplied, desired, live, strategic)
+    if len(patch) == 0 {
+        return nil, strategic, nil
+    }
+
+    encodedPatch, err := json.Marshal(patch)
+    return encodedPatch, strategic, err
+}
+
+func threeWayMerge(lastApplied, desired, live map[string]interface{}, strategic bool) map[string]interface{} {
+    patch := make(map[string]interface{})
+
+    for key, desiredValue := range desired {
+        liveValue := live[key]
+
+        desiredObject, desiredIsObject := desiredValue.(map[string]interface{})
+        liveObject, liveIsObject := liveValue.(map[string]interface{})
+
+        desiredList, desiredIsList := desiredValue.([]interface{})
+        liveList, liveIsList := liveValue.([]interface{})
+
+        if desiredIsObject && liveIsObject {
+            lastAppliedObject, _ := lastApplied[key].(map[string]interface{})
+            if objectPatch := threeWayMerge(lastAppliedObject, desiredObject, liveObject, strategic); len(objectPatch) != 0 {
+                patch[key] = objectPatch
+            }
+        } else if mergeKey := listMergeKey(key, desiredList); strategic && desiredIsList && liveIsList && mergeKey != "" {
+            lastAppliedList, _ := lastApplied[key].([]interface{})
+            if listPatch := threeWayMergeList(mergeKey, lastAppliedList, desiredList, liveList); len(listPatch) != 0 {
+                patch[key] = listPatch
+            }
+        } else if !reflect.DeepEqual(desiredValue, liveValue) {
+            patch[key] = desiredValue // other lists are replaced as a whole
+        }
+    }
+
+    for key := range lastApplied {
+        if _, desired := desired[key]; desired {
+            continue
+        }
+        if _, exists := live[key]; exists { // removed from the configuration since the last apply
+            patch[key] = nil
+        }
+    }
+
+    return patch
+}
+
+func threeWayMergeList(mergeKey string, lastApplied, desired, live []interface{}) []interface{} {
+    patch := make([]interface{}, 0)
+
+    for _, item := range desired {
+        desiredItem := item.(map[string]interface{})
+        keyValue := desiredItem[mergeKey]
+
+        liveItem := findListItem(live, mergeKey, keyValue)
+        if liveItem == nil {
+            patch = append(patch, desiredItem)
+            continue
+        }
+
+        if itemPatch := threeWayMerge(findListItem(lastApplied, mergeKey, keyValue), desiredItem, liveItem, true); len(itemPatch) != 0 {
+            itemPatch[mergeKey] = keyValue
+            patch = append(patch, itemPatch)
+        }
+    }
+
+    for _, item := range lastApplied { // items added by other tools (e.g. injected sidecars) are not in the last applied configuration
+        lastAppliedItem, ok := item.(map[string]interface{})
+        if !ok {
+            continue
+        }
+
+        keyValue := lastAppliedItem[mergeKey]
+        if findListItem(desired, mergeKey, keyValue) == nil && findListItem(live, mergeKey, keyValue) != nil {
+            patch = append(patch, map[string]interface{}{mergeKey: keyValue, "$patch": "delete"})
+        }
+    }
+
+    return patch
+}
+
+func listMergeKey(field string, items []interface{}) string { // "patchMergeKey" of the built-in types, empty if the list is replaced
+    mergeKey, ok := strategicMergeKeys[field]
+    if !ok || len(items) == 0 {
+        return ""
+    }
+
+    if field == "ports" { // container ports are keyed by "containerPort", service ports by "port"
+        if first, ok := items[0].(map[string]interface{}); ok {
+            if _, ok := first["containerPort"]; ok {
+                mergeKey = "containerPort"
+            }
+        }
+    }
+
+    if !allHaveKey(items, mergeKey) {
+        return ""
+    }
+
+    return mergeKey
+}
+
+func allHaveKey(items []interface{}, key string) bool {
+    for _, item := range items {
+        if object, ok := item.(map[string]interface{}); !ok || object[key] == nil {
+            return false
+        }
+    }
+    return true
+}
+
+func findListItem(items []interface{}, mergeKey string, keyValue interface{}) map[string]interface{} {
+    for _, item := range items {
+        if object, ok := item.(map[string]interface{}); ok && reflect.DeepEqual(object[mergeKey], keyValue) {
+            return object
+        }
+    }
+    return nil
+}
+
+func isBuiltInApiVersion(apiVersion string) bool {
+    if !strings.Contains(apiVersion, "/") {
+        return true // core group, e.g. "v1"
+    }
+    return builtInApiGroups[strings.SplitN(apiVersion, "/", 2)[0]]
+}
+
+func getAnnotations(object map[string]interface{}, create bool) map[string]interface{} {
+    metadata, ok := object["metadata"].(map[string]interface{})
+    if !ok {
+        if !create {
+            return nil
+        }
+        metadata = make(map[string]interface{})
+        object["metadata"] = metadata
+    }
+
+    annotations, ok := metadata["annotations"].(map[string]interface{})
+    if !ok {
+        if !create {
+            return nil
+        }
+        annotations = make(map[string]interface{})
+        metadata["annotations"] = annotations
+    }
+
+    return annotations
+}
@@ -0,0 +1,179 @@
+package kubernetes_model
+
+import (
+    "encoding/json"
+    "testing"
+)
+
+func TestThreeWayMergePatch(t *testing.T) {
+    tests := []struct {
+        name        string
+        lastApplied string // empty if the object has never been applied
+        desired     string
+        live        string
+        patch       string // empty if nothing is to be patched
+        strategic   bool
+    }{
+        {
+            name:        "no changes",
+            lastApplied: `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": "1"}}`,
+            desired:     `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": "1"}}`,
+            live:        `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": "1"}, "metadata": {"uid": "42"}}`,
+            strategic:   true,
+        },
+        {
+            name:        "changed and removed fields",
+            lastApplied: `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": "1", "b": "2"}}`,
+            desired:     `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": "3"}}`,
+            live:        `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": "1", "b": "2", "c": "4"}}`,
+            patch:       `{"data": {"a": "3", "b": null}}`,
+            strategic:   true,
+        },
+        {
+            name:      "fields set by other tools are kept without the last applied configuration",
+            desired:   `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": "1"}}`,
+            live:      `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": "1", "b": "2"}}`,
+            strategic: true,
+        },
+        {
+            name:        "server-defaulted fields of list items are kept",
+            lastApplied: `{"apiVersion": "apps/v1", "kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:1"}]}}}}`,
+            desired:     `{"apiVersion": "apps/v1", "kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:1"}]}}}}`,
+            live:        `{"apiVersion": "apps/v1", "kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:1", "imagePullPolicy": "IfNotPresent"}]}}}}`,
+            strategic:   true,
+        },
+        {
+            name:        "injected sidecar is kept",
+            lastApplied: `{"apiVersion": "apps/v1", "kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:1"}]}}}}`,
+            desired:     `{"apiVersion": "apps/v1", "kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:2"}]}}}}`,
+            live:        `{"apiVersion": "apps/v1", "kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:1"}, {"name": "istio-proxy", "image": "proxyv2"}]}}}}`,
+            patch:       `{"spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:2"}]}}}}`,
+            strategic:   true,
+        },
+        {
+            name:        "list items are added and deleted by key",
+            lastApplied: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "env": [{"name": "A", "value": "1"}, {"name": "B", "value": "2"}]}]}}`,
+            desired:     `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "env": [{"name": "A", "value": "1"}, {"name": "C", "value": "3"}]}]}}`,
+            live:        `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "env": [{"name": "A", "value": "1"}, {"name": "B", "value": "2"}]}]}}`,
+            patch:       `{"spec": {"containers": [{"name": "web", "env": [{"name": "C", "value": "3"}, {"name": "B", "$patch": "delete"}]}]}}`,
+            strategic:   true,
+        },
+        {
+            name:        "container ports are keyed by container port",
+            lastApplied: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "ports": [{"containerPort": 80}]}]}}`,
+            desired:     `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "ports": [{"containerPort": 80, "name": "http"}]}]}}`,
+            live:        `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "ports": [{"containerPort": 80, "protocol": "TCP"}]}]}}`,
+            patch:       `{"spec": {"containers": [{"name": "web", "ports": [{"containerPort": 80, "name": "http"}]}]}}`,
+            strategic:   true,
+        },
+        {
+            name:        "service ports are keyed by port",
+            lastApplied: `{"apiVersion": "v1", "kind": "Service", "spec": {"ports": [{"port": 80}, {"port": 443}]}}`,
+            desired:     `{"apiVersion": "v1", "kind": "Service", "spec": {"ports": [{"port": 80}]}}`,
+            live:        `{"apiVersion": "v1", "kind": "Service", "spec": {"ports": [{"port": 80, "targetPort": 80}, {"port": 443, "targetPort": 443}]}}`,
+            patch:       `{"spec": {"ports": [{"port": 443, "$patch": "delete"}]}}`,
+            strategic:   true,
+        },
+        {
+            name:        "lists without a merge key are replaced",
+            lastApplied: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "args": ["a"]}]}}`,
+            desired:     `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "args": ["b"]}]}}`,
+            live:        `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "args": ["a"]}]}}`,
+            patch:       `{"spec": {"containers": [{"name": "web", "args": ["b"]}]}}`,
+            strategic:   true,
+        },
+        {
+            name:        "custom resource lists are replaced",
+            lastApplied: `{"apiVersion": "example.com/v1", "kind": "App", "spec": {"containers": [{"name": "web", "image": "nginx:1"}]}}`,
+            desired:     `{"apiVersion": "example.com/v1", "kind": "App", "spec": {"containers": [{"name": "web", "image": "nginx:2"}]}}`,
+            live:        `{"apiVersion": "example.com/v1", "kind": "App", "spec": {"containers": [{"name": "web", "image": "nginx:1"}, {"name": "sidecar"}]}}`,
+            patch:       `{"spec": {"containers": [{"name": "web", "image": "nginx:2"}]}}`,
+            strategic:   false,
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            desired, err := WithLastAppliedConfig([]byte(test.desired), EncodingJson)
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            patch, strategic, err := ThreeWayMergePatch(desired, liveWithLastApplied(t, test.live, test.lastApplied))
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            if strategic != test.strategic {
+                t.Errorf("strategic merge patch: %v, expected %v", strategic, test.strategic)
+            }
+
+            patch = withoutLastAppliedConfig(t, patch)
+            switch {
+            case test.patch == "" && patch != nil:
+                t.Errorf("unexpected patch %s", patch)
+            case test.patch != "" && (patch == nil || !jsonEqual(t, patch, []byte(test.patch))):
+                t.Errorf("patch %s, expected %s", patch, test.patch)
+            }
+        })
+    }
+}
+
+func liveWithLastApplied(t *testing.T, live, lastApplied string) []byte {
+    if lastApplied == "" {
+        return []byte(live)
+    }
+
+    liveObject := make(map[string]interface{})
+    if err := json.Unmarshal([]byte(live), &liveObject); err != nil {
+        t.Fatalf("invalid JSON %s: %v", live, err)
+    }
+
+    lastAppliedContents, err := WithLastAppliedConfig([]byte(lastApplied), EncodingJson)
+    if err != nil {
+        t.Fatalf("invalid JSON %s: %v", lastApplied, err)
+    }
+
+    lastAppliedObject := make(map[string]interface{})
+    if err := json.Unmarshal(lastAppliedContents, &lastAppliedObject); err != nil {
+        t.Fatalf("invalid JSON %s: %v", lastAppliedContents, err)
+    }
+
+    getAnnotations(liveObject, true)[LastAppliedConfigAnnotation] = getAnnotations(lastAppliedObject, false)[LastAppliedConfigAnnotation]
+
+    contents, err := json.Marshal(liveObject)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return contents
+}
+
+func withoutLastAppliedConfig(t *testing.T, patch []byte) []byte { // the annotation is updated along with any change
+    if patch == nil {
+        return nil
+    }
+
+    patchObject := make(map[string]interface{})
+    if err := json.Unmarshal(patch, &patchObject); err != nil {
+        t.Fatalf("invalid JSON %s: %v", patch, err)
+    }
+
+    if annotations := getAnnotations(patchObject, false); annotations != nil {
+        delete(annotations, LastAppliedConfigAnnotation)
+        if metadata := patchObject["metadata"].(map[string]interface{}); len(annotations) == 0 {
+            if delete(metadata, "annotations"); len(metadata) == 0 {
+                delete(patchObject, "metadata")
+            }
+        }
+    }
+
+    if len(patchObject) == 0 {
+        return nil
+    }
+
+    contents, err := json.Marshal(patchObject)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return contents
+}
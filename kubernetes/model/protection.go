@@ -0,0 +1,76 @@
+package kubernetes_model
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+)
+
+const (
+    ProtectionRefuse  = "refuse"
+    ProtectionAbandon = "abandon"
+)
+
+type ProtectedObjects struct {
+    Namespaces     []string `json:",omitempty"`
+    Kinds          []string `json:",omitempty"`
+    LabelSelectors []string `json:",omitempty"`
+    OnDestroy      string
+}
+
+var DefaultProtectedObjects = &ProtectedObjects{
+    Namespaces: []string{DefaultNamespace, "kube-system", "kube-public"},
+    OnDestroy:  ProtectionAbandon,
+}
+
+type labeledObject struct {
+    Kind     string `json:"kind"`
+    Metadata struct {
+        Labels map[string]string `json:"labels"`
+    } `json:"metadata"`
+}
+
+func (protection *ProtectedObjects) IsAbandon() bool {
+    return protection.OnDestroy == ProtectionAbandon
+}
+
+func (protection *ProtectedObjects) NeedsLiveObject() bool {
+    return len(protection.Kinds) != 0 || len(protection.LabelSelectors) != 0
+}
+
+func (protection *ProtectedObjects) Match(resourcePath *KubeResourcePath, liveContents []byte) (string, error) { // returns the reason of protection
+    if resourcePath.IsNamespace() {
+        for _, namespace := range protection.Namespaces {
+            if resourcePath.Name == namespace {
+                return fmt.Sprintf("namespace \"%s\" is protected", namespace), nil
+            }
+        }
+    }
+
+    if liveContents == nil {
+        return "", nil
+    }
+
+    object := &labeledObject{}
+    if err := json.Unmarshal(liveContents, object); err != nil {
+        return "", err
+    }
+
+    for _, kind := range protection.Kinds {
+        if strings.EqualFold(kind, object.Kind) || strings.EqualFold(kind, resourcePath.Collection) {
+            return fmt.Sprintf("kind \"%s\" is protected", object.Kind), nil
+        }
+    }
+
+    for _, selector := range protection.LabelSelectors {
+        labelSelector, err := ParseLabelSelector(selector)
+        if err != nil {
+            return "", err
+        }
+        if labelSelector.Matches(object.Metadata.Labels) {
+            return fmt.Sprintf("labels match protected selector \"%s\"", selector), nil
+        }
+    }
+
+    return "", nil
+}
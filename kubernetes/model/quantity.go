@@ -0,0 +1,66 @@
+package kubernetes_model
+
+import (
+    "math/big"
+    "regexp"
+    "strings"
+)
+
+var quantityRegexp = regexp.MustCompile(`^([+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+))([eE][+-]?[0-9]+|Ki|Mi|Gi|Ti|Pi|Ei|n|u|m|k|M|G|T|P|E)?$`)
+
+var quantitySuffixes = map[string]string{
+    "":   "1",
+    "n":  "1/1000000000",
+    "u":  "1/1000000",
+    "m":  "1/1000",
+    "k":  "1000",
+    "M":  "1000000",
+    "G":  "1000000000",
+    "T":  "1000000000000",
+    "P":  "1000000000000000",
+    "E":  "1000000000000000000",
+    "Ki": "1024",
+    "Mi": "1048576",
+    "Gi": "1073741824",
+    "Ti": "1099511627776",
+    "Pi": "1125899906842624",
+    "Ei": "1152921504606846976",
+}
+
+func parseQuantity(value string) (*big.Rat, bool) { // resource quantities, e.g. "500m", "1Gi" or "1e3"
+    match := quantityRegexp.FindStringSubmatch(strings.TrimSpace(value))
+    if match == nil {
+        return nil, false
+    }
+
+    number, ok := new(big.Rat).SetString(match[1])
+    if !ok {
+        return nil, false
+    }
+
+    suffix := match[2]
+    if _, ok := quantitySuffixes[suffix]; !ok { // decimal exponent, e.g. "e3"
+        exponent, ok := new(big.Rat).SetString("1" + suffix)
+        if !ok {
+            return nil, false
+        }
+        return number.Mul(number, exponent), true
+    }
+
+    multiplier, _ := new(big.Rat).SetString(quantitySuffixes[suffix])
+    return number.Mul(number, multiplier), true
+}
+
+func quantitiesEqual(declared, live string) bool {
+    declaredQuantity, ok := parseQuantity(declared)
+    if !ok {
+        return false
+    }
+
+    liveQuantity, ok := parseQuantity(live)
+    if !ok {
+        return false
+    }
+
+    return declaredQuantity.Cmp(liveQuantity) == 0
+}
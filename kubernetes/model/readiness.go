@@ -0,0 +1,153 @@
+package kubernetes_model
+
+import (
+    "encoding/json"
+    "fmt"
+)
+
+type k8sObject struct {
+    Kind     string
+    Metadata struct {
+        Generation int64
+    }
+    Spec   map[string]interface{}
+    Status map[string]interface{}
+}
+
+func CheckReadiness(liveContents []byte) (string, error) { // empty status means ready, error means it never will be
+    object := &k8sObject{}
+    if err := json.Unmarshal(liveContents, object); err != nil {
+        return "", err
+    }
+
+    switch object.Kind {
+    case "Deployment":
+        if progressing := object.condition("Progressing"); progressing["status"] == "False" && progressing["reason"] == "ProgressDeadlineExceeded" {
+            return "", fmt.Errorf("Deployment has exceeded its progress deadline: %s", object.conditionMessage("Progressing"))
+        }
+        return object.rolloutStatus("replicas", "updatedReplicas", "availableReplicas"), nil
+    case "StatefulSet":
+        if updateStrategy, _ := object.Spec["updateStrategy"].(map[string]interface{}); updateStrategy["type"] == "OnDelete" {
+            return object.rolloutStatus("replicas", "", "readyReplicas"), nil // pods are not replaced until deleted manually
+        }
+        if status := object.rolloutStatus("replicas", "updatedReplicas", "readyReplicas"); status != "" {
+            return status, nil
+        }
+        if updateRevision := object.statusString("updateRevision"); updateRevision != "" && updateRevision != object.statusString("currentRevision") {
+            return fmt.Sprintf("waiting for revision %s to be rolled out", updateRevision), nil
+        }
+        return "", nil
+    case "DaemonSet":
+        if status := object.generationStatus(); status != "" {
+            return status, nil
+        }
+        desired := object.statusInt("desiredNumberScheduled")
+        if updated := object.statusInt("updatedNumberScheduled"); updated < desired {
+            return fmt.Sprintf("%d of %d pods updated", updated, desired), nil
+        }
+        if available := object.statusInt("numberAvailable"); available < desired {
+            return fmt.Sprintf("%d of %d pods available", available, desired), nil
+        }
+        return "", nil
+    case "Job":
+        if object.hasCondition("Failed") {
+            return "", fmt.Errorf("Job has failed: %s", object.conditionMessage("Failed"))
+        }
+        if !object.hasCondition("Complete") {
+            return "waiting for the job to complete", nil
+        }
+        return "", nil
+    case "Pod":
+        if phase := object.statusString("phase"); phase == "Failed" {
+            return "", fmt.Errorf("Pod has failed: %s", object.statusString("message"))
+        } else if phase == "Succeeded" {
+            return "", nil
+        }
+        if !object.hasCondition("Ready") {
+            return "waiting for the pod to become ready", nil
+        }
+        return "", nil
+    case "PersistentVolumeClaim":
+        if phase := object.statusString("phase"); phase != "Bound" {
+            return fmt.Sprintf("waiting for the claim to be bound, current phase is \"%s\"", phase), nil
+        }
+        return "", nil
+    case "Service":
+        if serviceType, _ := object.Spec["type"].(string); serviceType != "LoadBalancer" {
+            return "", nil
+        }
+        loadBalancer, _ := object.Status["loadBalancer"].(map[string]interface{})
+        if ingress, _ := loadBalancer["ingress"].([]interface{}); len(ingress) == 0 {
+            return "waiting for the load balancer ingress address", nil
+        }
+        return "", nil
+    case "CustomResourceDefinition":
+        if !object.hasCondition("Established") {
+            return "waiting for the resource definition to be established", nil
+        }
+        return "", nil
+    }
+
+    return "", nil
+}
+
+func (object *k8sObject) rolloutStatus(desiredField, updatedField, availableField string) string {
+    if status := object.generationStatus(); status != "" {
+        return status
+    }
+
+    desired := int64(1)
+    if replicas, ok := object.Spec[desiredField].(float64); ok {
+        desired = int64(replicas)
+    }
+
+    if updated := object.statusInt(updatedField); updatedField != "" && updated < desired {
+        return fmt.Sprintf("%d of %d replicas updated", updated, desired)
+    }
+
+    if total := object.statusInt(desiredField); total > desired {
+        return fmt.Sprintf("%d old replicas pending termination", total - desired)
+    }
+
+    if available := object.statusInt(availableField); available < desired {
+        return fmt.Sprintf("%d of %d replicas available", available, desired)
+    }
+
+    return ""
+}
+
+func (object *k8sObject) generationStatus() string {
+    if observed := object.statusInt("observedGeneration"); observed < object.Metadata.Generation {
+        return fmt.Sprintf("waiting for generation %d to be observed", object.Metadata.Generation)
+    }
+    return ""
+}
+
+func (object *k8sObject) statusInt(field string) int64 {
+    value, _ := object.Status[field].(float64)
+    return int64(value)
+}
+
+func (object *k8sObject) statusString(field string) string {
+    value, _ := object.Status[field].(string)
+    return value
+}
+
+func (object *k8sObject) condition(conditionType string) map[string]interface{} {
+    conditions, _ := object.Status["conditions"].([]interface{})
+    for _, rawCondition := range conditions {
+        if condition, ok := rawCondition.(map[string]interface{}); ok && condition["type"] == conditionType {
+            return condition
+        }
+    }
+    return nil
+}
+
+func (object *k8sObject) hasCondition(conditionType string) bool {
+    return object.condition(conditionType)["status"] == "True"
+}
+
+func (object *k8sObject) conditionMessage(conditionType string) string {
+    message, _ := object.condition(conditionType)["message"].(string)
+    return message
+}
@@ -0,0 +1,57 @@
+package kubernetes_model
+
+import (
+    "testing"
+)
+
+func expectStatus(t *testing.T, live, expected string) {
+    status, err := CheckReadiness([]byte(live))
+    if err != nil {
+        t.Errorf("CheckReadiness(%s) failed: %v", live, err)
+    } else if status != expected {
+        t.Errorf("CheckReadiness(%s) = \"%s\", expected \"%s\"", live, status, expected)
+    }
+}
+
+func expectFailure(t *testing.T, live string) {
+    if status, err := CheckReadiness([]byte(live)); err == nil {
+        t.Errorf("CheckReadiness(%s) = \"%s\", expected a failure", live, status)
+    }
+}
+
+func TestDeploymentReadiness(t *testing.T) {
+    expectStatus(t, `{"kind": "Deployment", "metadata": {"generation": 2}, "spec": {"replicas": 3}, "status": {"observedGeneration": 2, "replicas": 3, "updatedReplicas": 3, "availableReplicas": 3}}`, "")
+    expectStatus(t, `{"kind": "Deployment", "metadata": {"generation": 2}, "spec": {"replicas": 3}, "status": {"observedGeneration": 1, "replicas": 3, "updatedReplicas": 3, "availableReplicas": 3}}`, "waiting for generation 2 to be observed")
+    expectStatus(t, `{"kind": "Deployment", "metadata": {"generation": 1}, "spec": {"replicas": 3}, "status": {"observedGeneration": 1, "replicas": 3, "updatedReplicas": 1}}`, "1 of 3 replicas updated")
+    expectStatus(t, `{"kind": "Deployment", "metadata": {"generation": 1}, "spec": {"replicas": 3}, "status": {"observedGeneration": 1, "replicas": 4, "updatedReplicas": 3}}`, "1 old replicas pending termination")
+    expectStatus(t, `{"kind": "Deployment", "metadata": {"generation": 1}, "status": {"observedGeneration": 1, "replicas": 1, "updatedReplicas": 1}}`, "0 of 1 replicas available") // one replica by default
+    expectFailure(t, `{"kind": "Deployment", "status": {"replicas": 1, "updatedReplicas": 1, "conditions": [{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"}]}}`)
+}
+
+func TestStatefulSetAndDaemonSetReadiness(t *testing.T) {
+    expectStatus(t, `{"kind": "StatefulSet", "spec": {"replicas": 1}, "status": {"replicas": 1, "updatedReplicas": 1, "readyReplicas": 1, "currentRevision": "web-1", "updateRevision": "web-2"}}`, "waiting for revision web-2 to be rolled out")
+    expectStatus(t, `{"kind": "StatefulSet", "spec": {"replicas": 1}, "status": {"replicas": 1, "updatedReplicas": 1, "readyReplicas": 1, "currentRevision": "web-2", "updateRevision": "web-2"}}`, "")
+    expectStatus(t, `{"kind": "StatefulSet", "spec": {"replicas": 2, "updateStrategy": {"type": "OnDelete"}}, "status": {"replicas": 2, "updatedReplicas": 0, "readyReplicas": 2, "currentRevision": "web-1", "updateRevision": "web-2"}}`, "")
+    expectStatus(t, `{"kind": "StatefulSet", "spec": {"replicas": 2, "updateStrategy": {"type": "OnDelete"}}, "status": {"replicas": 2, "readyReplicas": 1}}`, "1 of 2 replicas available")
+    expectStatus(t, `{"kind": "DaemonSet", "status": {"desiredNumberScheduled": 3, "updatedNumberScheduled": 3, "numberAvailable": 2}}`, "2 of 3 pods available")
+}
+
+func TestJobAndPodReadiness(t *testing.T) {
+    expectStatus(t, `{"kind": "Job", "status": {"conditions": [{"type": "Complete", "status": "True"}]}}`, "")
+    expectStatus(t, `{"kind": "Job", "status": {"active": 1}}`, "waiting for the job to complete")
+    expectFailure(t, `{"kind": "Job", "status": {"conditions": [{"type": "Failed", "status": "True", "message": "BackoffLimitExceeded"}]}}`)
+
+    expectStatus(t, `{"kind": "Pod", "status": {"phase": "Running", "conditions": [{"type": "Ready", "status": "False"}]}}`, "waiting for the pod to become ready")
+    expectStatus(t, `{"kind": "Pod", "status": {"phase": "Succeeded"}}`, "")
+    expectFailure(t, `{"kind": "Pod", "status": {"phase": "Failed", "message": "OOMKilled"}}`)
+}
+
+func TestOtherReadiness(t *testing.T) {
+    expectStatus(t, `{"kind": "PersistentVolumeClaim", "status": {"phase": "Pending"}}`, "waiting for the claim to be bound, current phase is \"Pending\"")
+    expectStatus(t, `{"kind": "Service", "spec": {"type": "ClusterIP"}}`, "")
+    expectStatus(t, `{"kind": "Service", "spec": {"type": "LoadBalancer"}, "status": {"loadBalancer": {}}}`, "waiting for the load balancer ingress address")
+    expectStatus(t, `{"kind": "Service", "spec": {"type": "LoadBalancer"}, "status": {"loadBalancer": {"ingress": [{"ip": "10.0.0.1"}]}}}`, "")
+    expectStatus(t, `{"kind": "CustomResourceDefinition", "status": {"conditions": [{"type": "NamesAccepted", "status": "True"}]}}`, "waiting for the resource definition to be established")
+    expectStatus(t, `{"kind": "ConfigMap"}`, "")
+    expectFailure(t, `[]`)
+}
@@ -0,0 +1,118 @@
+package kubernetes_model
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+)
+
+type labelRequirement struct {
+    key      string
+    operator string
+    values   []string
+}
+
+type LabelSelector []*labelRequirement
+
+var (
+    setRequirementPattern      = regexp.MustCompile(`^([^\s!=(),]+)\s+(in|notin)\s+\(([^()]*)\)$`)
+    equalityRequirementPattern = regexp.MustCompile(`^([^\s!=(),]+)\s*(==|!=|=)\s*([^\s!=(),]*)$`)
+    existsRequirementPattern   = regexp.MustCompile(`^(!?)([^\s!=(),]+)$`)
+)
+
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+    labelSelector := make(LabelSelector, 0)
+
+    for _, requirement := range splitSelector(selector) {
+        if requirement = strings.TrimSpace(requirement); requirement == "" {
+            continue
+        }
+
+        if match := setRequirementPattern.FindStringSubmatch(requirement); match != nil {
+            values := make([]string, 0)
+            for _, value := range strings.Split(match[3], ",") {
+                if value = strings.TrimSpace(value); value != "" {
+                    values = append(values, value)
+                }
+            }
+            labelSelector = append(labelSelector, &labelRequirement{key: match[1], operator: match[2], values: values})
+        } else if match := equalityRequirementPattern.FindStringSubmatch(requirement); match != nil {
+            operator := "in"
+            if match[2] == "!=" {
+                operator = "notin"
+            }
+            labelSelector = append(labelSelector, &labelRequirement{key: match[1], operator: operator, values: []string{match[3]}})
+        } else if match := existsRequirementPattern.FindStringSubmatch(requirement); match != nil {
+            operator := "exists"
+            if match[1] == "!" {
+                operator = "!exists"
+            }
+            labelSelector = append(labelSelector, &labelRequirement{key: match[2], operator: operator})
+        } else {
+            return nil, fmt.Errorf("Invalid label selector requirement: %s", requirement)
+        }
+    }
+
+    if len(labelSelector) == 0 {
+        return nil, fmt.Errorf("Empty label selector: \"%s\"", selector)
+    }
+
+    return labelSelector, nil
+}
+
+func (selector LabelSelector) Matches(labels map[string]string) bool {
+    for _, requirement := range selector {
+        value, exists := labels[requirement.key]
+
+        switch requirement.operator {
+        case "exists":
+            if !exists {
+                return false
+            }
+        case "!exists":
+            if exists {
+                return false
+            }
+        case "in":
+            if !exists || !contains(requirement.values, value) {
+                return false
+            }
+        case "notin":
+            if exists && contains(requirement.values, value) {
+                return false
+            }
+        }
+    }
+
+    return true
+}
+
+func splitSelector(selector string) []string { // splits by commas which are not inside of "(...)"
+    requirements := make([]string, 0)
+
+    depth, start := 0, 0
+    for i, c := range selector {
+        switch c {
+        case '(':
+            depth++
+        case ')':
+            depth--
+        case ',':
+            if depth == 0 {
+                requirements = append(requirements, selector[start:i])
+                start = i + 1
+            }
+        }
+    }
+
+    return append(requirements, selector[start:])
+}
+
+func contains(values []string, value string) bool {
+    for _, v := range values {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,66 @@
+package kubernetes_model
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+    requirements := map[string]int{
+        "app=web":                             1,
+        "app == web":                          1,
+        "app!=web":                            1,
+        "app":                                 1,
+        "!app":                                1,
+        "app=":                                1,
+        "env in (prod, staging)":              1,
+        "example.com/protected=true":          1,
+        "env notin (dev),tier=front, !canary": 3,
+    }
+
+    for selector, expected := range requirements {
+        if parsed, err := ParseLabelSelector(selector); err != nil {
+            t.Errorf("\"%s\" is not parsed: %v", selector, err)
+        } else if len(parsed) != expected {
+            t.Errorf("\"%s\" has %d requirements, expected %d", selector, len(parsed), expected)
+        }
+    }
+
+    for _, selector := range []string{"", " , ", "app=web=front", "env in prod", "env in (prod"} {
+        if _, err := ParseLabelSelector(selector); err == nil {
+            t.Errorf("\"%s\" must be rejected", selector)
+        }
+    }
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+    objects := map[string]map[string]string{
+        "web":       {"app": "web", "env": "prod", "tier": "front"},
+        "db":        {"app": "db", "env": "staging"},
+        "unlabeled": nil,
+    }
+
+    matches := map[string]string{ // selector -> objects it matches
+        "app=web":                  "web",
+        "app!=web":                 "db unlabeled",
+        "tier":                     "web",
+        "!tier":                    "db unlabeled",
+        "env in (prod, staging)":   "web db",
+        "env notin (prod)":         "db unlabeled",
+        "env in (prod),tier=front": "web",
+        "app=web,app=db":           "",
+    }
+
+    for selector, expected := range matches {
+        parsed, err := ParseLabelSelector(selector)
+        if err != nil {
+            t.Fatal(err)
+        }
+
+        for name, labels := range objects {
+            if actual, want := parsed.Matches(labels), contains(strings.Fields(expected), name); actual != want {
+                t.Errorf("\"%s\" on %s: %v, expected %v", selector, name, actual, want)
+            }
+        }
+    }
+}
@@ -1,6 +1,7 @@
 package kubernetes_model
 
 import (
+    "bytes"
     "encoding/json"
     "errors"
     "fmt"
@@ -12,7 +13,7 @@ import (
 type k8sEntity struct {
     ApiVersion string `json:"apiVersion" yaml:"apiVersion"`
     Kind       string
-    Metadata struct {
+    Metadata   struct {
         Name      string
         Namespace string
     }
@@ -37,25 +38,84 @@ func (e *k8sEntity) GetNamespace(global bool) string {
     if e.Metadata.Namespace == "" {
         return DefaultNamespace
     }
-    
+
     return e.Metadata.Namespace
 }
 
-func (e *k8sEntity) GetCollection() string {
+func (e *k8sEntity) GetCollection() string { // only a guess, the real collection name comes from API discovery
     lowerKind := strings.ToLower(e.Kind)
+    if lowerKind == "endpoints" {
+        return lowerKind
+    }
     if strings.HasSuffix(lowerKind, "s") {
         return fmt.Sprintf("%ses", lowerKind)
     }
+    if strings.HasSuffix(lowerKind, "y") && !strings.HasSuffix(lowerKind, "ay") && !strings.HasSuffix(lowerKind, "ey") {
+        return fmt.Sprintf("%sies", strings.TrimSuffix(lowerKind, "y"))
+    }
     return fmt.Sprintf("%ss", lowerKind)
 }
 
 func ParseResource(resourceData *schema.ResourceData) (*KubeResource, error) {
     contents := []byte(resourceData.Get("contents").(string))
-    encoding := resourceData.Get("encoding").(string)
+    options := parseOptions(resourceData)
+    global := resourceData.Get("global").(bool)
+
+    return parseSingleResource(contents, options, global)
+}
+
+func ParseResources(resourceData *schema.ResourceData) ([]*KubeResource, error) {
+    contents := []byte(resourceData.Get("contents").(string))
+    options := parseOptions(resourceData)
     global := resourceData.Get("global").(bool)
 
+    documents := splitDocuments(contents, options.Encoding)
+    if len(documents) == 0 {
+        return nil, errors.New("No resources specified")
+    }
+
+    resources := make([]*KubeResource, 0, len(documents))
+    paths := make(map[string]bool)
+
+    for i, document := range documents {
+        resource, err := parseResource(document, options, global)
+        if err != nil {
+            return nil, fmt.Errorf("Document #%d: %v", i + 1, err)
+        }
+
+        path := resource.Path()
+        if paths[path] {
+            return nil, fmt.Errorf("Document #%d: duplicate resource %s", i + 1, path)
+        }
+        paths[path] = true
+
+        resources = append(resources, resource)
+    }
+
+    return resources, nil
+}
+
+func parseOptions(resourceData *schema.ResourceData) *KubeResourceOptions {
+    return &KubeResourceOptions{
+        Encoding:       resourceData.Get("encoding").(string),
+        ApplyMode:      resourceData.Get("apply_mode").(string),
+        FieldManager:   resourceData.Get("field_manager").(string),
+        ForceConflicts: resourceData.Get("force_conflicts").(bool),
+        UpdateStrategy: resourceData.Get("update_strategy").(string),
+        WaitForReady:   resourceData.Get("wait_for_ready").(bool),
+    }
+}
+
+func parseSingleResource(contents []byte, options *KubeResourceOptions, global bool) (*KubeResource, error) {
+    if documents := splitDocuments(contents, options.Encoding); len(documents) > 1 {
+        return nil, fmt.Errorf("\"contents\" has %d documents while k8s_resource manages a single object, use k8s_manifest_bundle for multi-document manifests", len(documents))
+    }
+    return parseResource(contents, options, global)
+}
+
+func parseResource(contents []byte, options *KubeResourceOptions, global bool) (*KubeResource, error) {
     entity := &k8sEntity{}
-    if encoding == EncodingJson {
+    if options.Encoding == EncodingJson {
         if err := json.Unmarshal(contents, entity); err != nil {
             return nil, err
         }
@@ -80,11 +140,60 @@ func ParseResource(resourceData *schema.ResourceData) (*KubeResource, error) {
             Collection: entity.GetCollection(),
             Name:       entity.Metadata.Name,
         },
-        Contents: contents,
-        Encoding: encoding,
+        KubeResourceOptions: options,
+        ApiVersion:          entity.ApiVersion,
+        Kind:                entity.Kind,
+        Contents:            contents,
+        declaredNamespace:   entity.Metadata.Namespace,
     }, nil
 }
 
+func splitDocuments(contents []byte, encoding string) [][]byte {
+    if encoding == EncodingJson {
+        if len(bytes.TrimSpace(contents)) == 0 {
+            return nil
+        }
+        return [][]byte{contents}
+    }
+
+    documents := make([][]byte, 0)
+    document := make([]byte, 0, len(contents))
+
+    appendDocument := func() {
+        if isEmptyYamlDocument(document) {
+            return
+        }
+        documents = append(documents, document)
+    }
+
+    for _, line := range bytes.SplitAfter(contents, []byte("\n")) {
+        if isYamlDocumentSeparator(line) {
+            appendDocument()
+            document = make([]byte, 0, len(contents))
+        } else {
+            document = append(document, line...)
+        }
+    }
+
+    appendDocument()
+
+    return documents
+}
+
+func isYamlDocumentSeparator(line []byte) bool {
+    line = bytes.TrimRight(line, " \t\r\n")
+    return bytes.Equal(line, []byte("---")) || bytes.HasPrefix(line, []byte("--- ")) || bytes.HasPrefix(line, []byte("---\t"))
+}
+
+func isEmptyYamlDocument(document []byte) bool {
+    for _, line := range bytes.Split(document, []byte("\n")) {
+        if line = bytes.TrimSpace(line); len(line) != 0 && line[0] != '#' {
+            return false
+        }
+    }
+    return true
+}
+
 func ParsePath(path string) *KubeResourcePath {
     resourceName, collectionPath := splitOne(path)
     collectionName, restPath := splitOne(collectionPath)
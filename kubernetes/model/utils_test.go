@@ -0,0 +1,59 @@
+package kubernetes_model
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestSplitYamlDocuments(t *testing.T) {
+    cases := map[string][]string{
+        "kind: Pod\n":                     {"kind: Pod\n"},
+        "kind: Pod\n---\nkind: Service\n": {"kind: Pod\n", "kind: Service\n"},
+        "---\nkind: Pod\n":                {"kind: Pod\n"},
+        "kind: Pod\n--- # service\nkind: Service\n---  \r\n": {"kind: Pod\n", "kind: Service\n"},
+        "# header\n---\n\n---\nkind: Pod\n---\n# trailer\n":  {"kind: Pod\n"},
+        "data: |\n  ---\n  text\nkind: ConfigMap\n":          {"data: |\n  ---\n  text\nkind: ConfigMap\n"},
+        "kind: Pod\n---\nkind: Service":                      {"kind: Pod\n", "kind: Service"},
+        "":                                                   {},
+    }
+
+    for contents, expected := range cases {
+        if documents := documentStrings(splitDocuments([]byte(contents), EncodingYaml)); !reflect.DeepEqual(documents, expected) {
+            t.Errorf("splitDocuments(%q) = %q, expected %q", contents, documents, expected)
+        }
+    }
+}
+
+func TestSplitJsonDocuments(t *testing.T) {
+    contents := "{\"kind\": \"Pod\"}\n---\n"
+    if documents := documentStrings(splitDocuments([]byte(contents), EncodingJson)); !reflect.DeepEqual(documents, []string{contents}) {
+        t.Errorf("JSON must not be split, got %q", documents)
+    }
+
+    if documents := splitDocuments([]byte(" \n"), EncodingJson); len(documents) != 0 {
+        t.Errorf("blank JSON must produce no documents, got %q", documents)
+    }
+}
+
+func documentStrings(documents [][]byte) []string {
+    result := make([]string, 0, len(documents))
+    for _, document := range documents {
+        result = append(result, string(document))
+    }
+    return result
+}
+
+func TestParseSingleResource(t *testing.T) {
+    options := &KubeResourceOptions{Encoding: EncodingYaml}
+
+    if resource, err := parseSingleResource([]byte("---\nkind: Pod\nmetadata:\n  name: web\n"), options, false); err != nil {
+        t.Errorf("single document is rejected: %v", err)
+    } else if resource.Path() != "api/v1/namespaces/default/pods/web" {
+        t.Errorf("unexpected path %s", resource.Path())
+    }
+
+    if _, err := parseSingleResource([]byte("kind: Pod\nmetadata:\n  name: web\n---\nkind: Service\nmetadata:\n  name: web\n"), options, false); err == nil || !strings.Contains(err.Error(), "k8s_manifest_bundle") {
+        t.Errorf("multiple documents must be rejected with a hint, got %v", err)
+    }
+}
@@ -6,9 +6,37 @@ import (
     "github.com/hashicorp/terraform/terraform"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
+    "net/url"
     "strings"
+    "time"
 )
 
+var defaultTimeouts = map[string]time.Duration{
+    schema.TimeoutCreate: 10 * time.Minute,
+    schema.TimeoutRead:   1 * time.Minute,
+    schema.TimeoutUpdate: 10 * time.Minute,
+    schema.TimeoutDelete: 5 * time.Minute,
+}
+
+var providerEnvVars = map[string]string{ // explicit attributes > environment variables > kubeconfig
+    "api_server":             "KUBE_API_SERVER",
+    "ca_cert":                "KUBE_CA_CERT",
+    "client_cert":            "KUBE_CLIENT_CERT",
+    "client_key":             "KUBE_CLIENT_KEY",
+    "tls_server_name":        "KUBE_TLS_SERVER_NAME",
+    "proxy_url":              "KUBE_PROXY_URL",
+    "token":                  "KUBE_TOKEN",
+    "token_file":             "KUBE_TOKEN_FILE",
+    "username":               "KUBE_USERNAME",
+    "password":               "KUBE_PASSWORD",
+    "impersonate_user":       "KUBE_IMPERSONATE_USER",
+    "config_path":            "KUBE_CONFIG_PATH",
+    "config_context":         "KUBE_CONFIG_CONTEXT",
+    "config_context_cluster": "KUBE_CONFIG_CONTEXT_CLUSTER",
+    "config_context_user":    "KUBE_CONFIG_CONTEXT_USER",
+    "namespace":              "KUBE_NAMESPACE",
+}
+
 func Provider() terraform.ResourceProvider {
     return &schema.Provider{
         Schema:        clusterSchema(false),
@@ -21,41 +49,34 @@ func Provider() terraform.ResourceProvider {
                 Read:   readKubernetesCluster,
                 Update: updateKubernetesCluster,
                 Delete: deleteKubernetesCluster,
+                Timeouts: &schema.ResourceTimeout{
+                    Create: schema.DefaultTimeout(defaultTimeouts[schema.TimeoutCreate]),
+                    Read:   schema.DefaultTimeout(defaultTimeouts[schema.TimeoutRead]),
+                    Update: schema.DefaultTimeout(defaultTimeouts[schema.TimeoutUpdate]),
+                },
             },
 
             "k8s_resource": {
-                Schema: map[string]*schema.Schema{
-                    "cluster": {
-                        Type:      schema.TypeString,
-                        Sensitive: true,
-                        Optional:  true,
-                    },
-                    "contents": {
-                        Type:      schema.TypeString,
-                        Required:  true,
-                        Sensitive: true,
-                    },
-                    "encoding": {
-                        Type:         schema.TypeString,
-                        Optional:     true,
-                        Default:      kubernetes_model.EncodingYaml,
-                        ValidateFunc: validateResourceEncoding,
-                    },
-                    "global": {
-                        Type:     schema.TypeBool,
-                        Optional: true,
-                        Default:  false,
-                    },
-                    "path": {
-                        Type:     schema.TypeString,
-                        Computed: true,
-                    },
-                },
+                Schema: resourceSchema(false),
                 Create: createKubernetesResource,
                 Read:   readKubernetesResource,
                 Update: updateKubernetesResource,
                 Delete: deleteKubernetesResource,
                 Exists: kubernetesResourceExists,
+                Importer: &schema.ResourceImporter{
+                    State: importKubernetesResource,
+                },
+                Timeouts: resourceTimeouts(),
+            },
+
+            "k8s_manifest_bundle": {
+                Schema:   resourceSchema(true),
+                Create:   createKubernetesManifestBundle,
+                Read:     readKubernetesManifestBundle,
+                Update:   updateKubernetesManifestBundle,
+                Delete:   deleteKubernetesManifestBundle,
+                Exists:   kubernetesManifestBundleExists,
+                Timeouts: resourceTimeouts(),
             },
         },
     }
@@ -65,8 +86,7 @@ func clusterSchema(clusterResource bool) map[string]*schema.Schema {
     clusterSchema := map[string]*schema.Schema{
         "api_server": {
             Type:     schema.TypeString,
-            Required: clusterResource,
-            Optional: !clusterResource,
+            Optional: true,
         },
         "ca_cert": {
             Type:      schema.TypeString,
@@ -85,17 +105,404 @@ func clusterSchema(clusterResource bool) map[string]*schema.Schema {
         },
     }
 
+    clusterSchema["insecure"] = &schema.Schema{
+        Type:     schema.TypeBool,
+        Optional: true,
+        Default:  false,
+    }
+
+    clusterSchema["tls_server_name"] = &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+    }
+
+    clusterSchema["tls_min_version"] = &schema.Schema{
+        Type:         schema.TypeString,
+        Optional:     true,
+        ValidateFunc: validateTlsVersion,
+    }
+
+    clusterSchema["proxy_url"] = &schema.Schema{
+        Type:         schema.TypeString,
+        Optional:     true,
+        ValidateFunc: validateProxyUrl,
+    }
+
+    clusterSchema["request_timeout"] = &schema.Schema{
+        Type:         schema.TypeString,
+        Optional:     true,
+        ValidateFunc: validateDuration,
+    }
+
+    clusterSchema["ssh_tunnel"] = &schema.Schema{
+        Type:     schema.TypeList,
+        Optional: true,
+        MaxItems: 1,
+        Elem: &schema.Resource{
+            Schema: map[string]*schema.Schema{
+                "host": {
+                    Type:     schema.TypeString,
+                    Required: true,
+                },
+                "user": {
+                    Type:     schema.TypeString,
+                    Required: true,
+                },
+                "private_key": {
+                    Type:      schema.TypeString,
+                    Optional:  true,
+                    Sensitive: true,
+                },
+                "known_hosts": {
+                    Type:     schema.TypeString,
+                    Optional: true,
+                },
+            },
+        },
+    }
+
+    clusterSchema["token"] = &schema.Schema{
+        Type:      schema.TypeString,
+        Optional:  true,
+        Sensitive: true,
+    }
+
+    clusterSchema["token_file"] = &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+    }
+
+    clusterSchema["username"] = &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+    }
+
+    clusterSchema["password"] = &schema.Schema{
+        Type:      schema.TypeString,
+        Optional:  true,
+        Sensitive: true,
+    }
+
+    clusterSchema["exec"] = &schema.Schema{
+        Type:          schema.TypeList,
+        Optional:      true,
+        MaxItems:      1,
+        ConflictsWith: []string{"eks"},
+        Elem: &schema.Resource{
+            Schema: map[string]*schema.Schema{
+                "api_version": {
+                    Type:         schema.TypeString,
+                    Optional:     true,
+                    Default:      kubernetes_cluster.DefaultExecApiVersion,
+                    ValidateFunc: validateExecApiVersion,
+                },
+                "command": {
+                    Type:     schema.TypeString,
+                    Required: true,
+                },
+                "args": {
+                    Type:     schema.TypeList,
+                    Elem:     &schema.Schema{Type: schema.TypeString},
+                    Optional: true,
+                },
+                "env": {
+                    Type:     schema.TypeMap,
+                    Elem:     &schema.Schema{Type: schema.TypeString},
+                    Optional: true,
+                },
+            },
+        },
+    }
+
+    clusterSchema["impersonate_user"] = &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+    }
+
+    clusterSchema["impersonate_groups"] = &schema.Schema{
+        Type:     schema.TypeList,
+        Elem:     &schema.Schema{Type: schema.TypeString},
+        Optional: true,
+    }
+
+    clusterSchema["impersonate_extra"] = &schema.Schema{
+        Type:     schema.TypeMap,
+        Elem:     &schema.Schema{Type: schema.TypeString},
+        Optional: true,
+    }
+
+    clusterSchema["eks"] = &schema.Schema{
+        Type:          schema.TypeList,
+        Optional:      true,
+        MaxItems:      1,
+        ConflictsWith: []string{"exec"},
+        Elem: &schema.Resource{
+            Schema: map[string]*schema.Schema{
+                "cluster_name": {
+                    Type:     schema.TypeString,
+                    Required: true,
+                },
+                "region": {
+                    Type:     schema.TypeString,
+                    Optional: true,
+                },
+                "role_arn": {
+                    Type:     schema.TypeString,
+                    Optional: true,
+                },
+            },
+        },
+    }
+
+    clusterSchema["config_path"] = &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+    }
+
+    for _, attribute := range []string{"config_context", "config_context_cluster", "config_context_user"} {
+        clusterSchema[attribute] = &schema.Schema{
+            Type:     schema.TypeString,
+            Optional: true,
+        }
+    }
+
+    clusterSchema["namespace"] = &schema.Schema{
+        Type:     schema.TypeString,
+        Optional: true,
+    }
+
+    clusterSchema["protected_objects"] = &schema.Schema{
+        Type:     schema.TypeList,
+        Optional: true,
+        MaxItems: 1,
+        Elem: &schema.Resource{
+            Schema: map[string]*schema.Schema{
+                "namespaces": {
+                    Type:     schema.TypeList,
+                    Elem:     &schema.Schema{Type: schema.TypeString},
+                    Optional: true,
+                },
+                "kinds": {
+                    Type:     schema.TypeList,
+                    Elem:     &schema.Schema{Type: schema.TypeString},
+                    Optional: true,
+                },
+                "label_selectors": {
+                    Type:     schema.TypeList,
+                    Elem:     &schema.Schema{Type: schema.TypeString, ValidateFunc: validateLabelSelector},
+                    Optional: true,
+                },
+                "on_destroy": {
+                    Type:         schema.TypeString,
+                    Optional:     true,
+                    Default:      kubernetes_model.ProtectionRefuse,
+                    ValidateFunc: validateProtectionAction,
+                },
+            },
+        },
+    }
+
+    clusterSchema["cert_expiry_warning"] = &schema.Schema{
+        Type:         schema.TypeString,
+        Optional:     true,
+        ValidateFunc: validateDuration,
+    }
+
+    clusterSchema["retry_max_interval"] = &schema.Schema{
+        Type:         schema.TypeString,
+        Optional:     true,
+        ValidateFunc: validateDuration,
+    }
+
+    for _, key := range kubernetes_cluster.TimeoutKeys { // defaults for the "timeouts" blocks of the resources
+        clusterSchema[kubernetes_cluster.TimeoutAttribute(key)] = &schema.Schema{
+            Type:         schema.TypeString,
+            Optional:     true,
+            ValidateFunc: validateDuration,
+        }
+    }
+
+    if !clusterResource {
+        clusterSchema["cluster_encryption_key"] = &schema.Schema{
+            Type:        schema.TypeString,
+            Optional:    true,
+            Sensitive:   true,
+            DefaultFunc: schema.EnvDefaultFunc("KUBE_CLUSTER_ENCRYPTION_KEY", nil),
+        }
+
+        for attribute, envVar := range providerEnvVars {
+            clusterSchema[attribute].DefaultFunc = schema.EnvDefaultFunc(envVar, nil)
+        }
+    }
+
     if clusterResource {
         clusterSchema["cluster"] = &schema.Schema{
             Type:      schema.TypeString,
             Sensitive: true,
             Computed:  true,
         }
+
+        for _, attribute := range []string{"server_version", "git_version", "platform"} {
+            clusterSchema[attribute] = &schema.Schema{
+                Type:     schema.TypeString,
+                Computed: true,
+            }
+        }
+
+        clusterSchema["api_versions"] = &schema.Schema{
+            Type:     schema.TypeList,
+            Elem:     &schema.Schema{Type: schema.TypeString},
+            Computed: true,
+        }
+
+        clusterSchema["kubeconfig"] = &schema.Schema{
+            Type:      schema.TypeString,
+            Sensitive: true,
+            Computed:  true,
+        }
+        clusterSchema["kubeconfig_context_name"] = &schema.Schema{
+            Type:     schema.TypeString,
+            Optional: true,
+            Default:  kubernetes_cluster.DefaultKubeConfigContextName,
+        }
+
+        for _, attribute := range []string{"client_cert_not_after", "ca_cert_not_after"} {
+            clusterSchema[attribute] = &schema.Schema{
+                Type:     schema.TypeString,
+                Computed: true,
+            }
+        }
+
+        clusterSchema["wait_for_readyz"] = &schema.Schema{ // health gate on creation
+            Type:     schema.TypeBool,
+            Optional: true,
+            Default:  false,
+        }
+        clusterSchema["min_ready_nodes"] = &schema.Schema{
+            Type:     schema.TypeInt,
+            Optional: true,
+            Default:  0,
+        }
+        clusterSchema["wait_for_system_deployments"] = &schema.Schema{
+            Type:     schema.TypeList,
+            Elem:     &schema.Schema{Type: schema.TypeString},
+            Optional: true,
+        }
     }
 
     return clusterSchema
 }
 
+func resourceSchema(bundle bool) map[string]*schema.Schema {
+    resourceSchema := map[string]*schema.Schema{
+        "cluster": {
+            Type:      schema.TypeString,
+            Sensitive: true,
+            Optional:  true,
+        },
+        "contents": {
+            Type:      schema.TypeString,
+            Required:  true,
+            Sensitive: true,
+        },
+        "encoding": {
+            Type:         schema.TypeString,
+            Optional:     true,
+            Default:      kubernetes_model.EncodingYaml,
+            ValidateFunc: validateResourceEncoding,
+        },
+        "global": {
+            Type:     schema.TypeBool,
+            Optional: true,
+            Default:  false,
+        },
+        "apply_mode": {
+            Type:         schema.TypeString,
+            Optional:     true,
+            Default:      kubernetes_model.ApplyModeClientSide,
+            ValidateFunc: validateResourceApplyMode,
+        },
+        "field_manager": {
+            Type:     schema.TypeString,
+            Optional: true,
+            Default:  kubernetes_model.DefaultFieldManager,
+        },
+        "force_conflicts": {
+            Type:     schema.TypeBool,
+            Optional: true,
+            Default:  false,
+        },
+        "update_strategy": {
+            Type:         schema.TypeString,
+            Optional:     true,
+            Default:      kubernetes_model.UpdateStrategyReplace,
+            ValidateFunc: validateResourceUpdateStrategy,
+        },
+        "wait_for_ready": {
+            Type:     schema.TypeBool,
+            Optional: true,
+            Default:  false,
+        },
+        "deletion_propagation": {
+            Type:         schema.TypeString,
+            Optional:     true,
+            ValidateFunc: validateResourceDeletionPropagation,
+        },
+        "prevent_destroy_in_cluster": {
+            Type:          schema.TypeBool,
+            Optional:      true,
+            Default:       false,
+            ConflictsWith: []string{"abandon_on_destroy"},
+        },
+        "abandon_on_destroy": {
+            Type:          schema.TypeBool,
+            Optional:      true,
+            Default:       false,
+            ConflictsWith: []string{"prevent_destroy_in_cluster"},
+        },
+        "force_finalize": {
+            Type:     schema.TypeBool,
+            Optional: true,
+            Default:  false,
+        },
+        "force_finalize_grace_period": {
+            Type:         schema.TypeString,
+            Optional:     true,
+            Default:      kubernetes_model.DefaultForceFinalizeGracePeriod,
+            ValidateFunc: validateDuration,
+        },
+        "timeouts": timeoutsSchema(),
+    }
+
+    if bundle {
+        resourceSchema["paths"] = &schema.Schema{
+            Type:     schema.TypeList,
+            Elem:     &schema.Schema{Type: schema.TypeString},
+            Computed: true,
+        }
+        resourceSchema["destroy_outcomes"] = &schema.Schema{
+            Type:     schema.TypeMap,
+            Computed: true,
+        }
+    } else {
+        resourceSchema["path"] = &schema.Schema{
+            Type:     schema.TypeString,
+            Computed: true,
+        }
+        resourceSchema["drifted_fields"] = &schema.Schema{
+            Type:     schema.TypeList,
+            Elem:     &schema.Schema{Type: schema.TypeString},
+            Computed: true,
+        }
+        resourceSchema["destroy_outcome"] = &schema.Schema{
+            Type:     schema.TypeString,
+            Computed: true,
+        }
+    }
+
+    return resourceSchema
+}
+
 func validateResourceEncoding(v interface{}, _ string) ([]string, []error) {
     if value := strings.ToLower(v.(string)); value != kubernetes_model.EncodingJson && value != kubernetes_model.EncodingYaml {
         return nil, []error{
@@ -105,6 +512,128 @@ func validateResourceEncoding(v interface{}, _ string) ([]string, []error) {
     return nil, nil
 }
 
+func validateResourceApplyMode(v interface{}, _ string) ([]string, []error) {
+    if value := v.(string); value != kubernetes_model.ApplyModeClientSide && value != kubernetes_model.ApplyModeServerSide {
+        return nil, []error{
+            fmt.Errorf("Invalid apply mode: %v; possible values are \"%s\" and \"%s\"", v, kubernetes_model.ApplyModeClientSide, kubernetes_model.ApplyModeServerSide),
+        }
+    }
+    return nil, nil
+}
+
+func validateResourceUpdateStrategy(v interface{}, _ string) ([]string, []error) {
+    switch v.(string) {
+    case kubernetes_model.UpdateStrategyReplace, kubernetes_model.UpdateStrategyMerge, kubernetes_model.UpdateStrategyStrategic, kubernetes_model.UpdateStrategyThreeWay:
+        return nil, nil
+    }
+    return nil, []error{
+        fmt.Errorf("Invalid update strategy: %v; possible values are \"%s\", \"%s\", \"%s\" and \"%s\"", v, kubernetes_model.UpdateStrategyReplace, kubernetes_model.UpdateStrategyMerge, kubernetes_model.UpdateStrategyStrategic, kubernetes_model.UpdateStrategyThreeWay),
+    }
+}
+
+func validateResourceDeletionPropagation(v interface{}, _ string) ([]string, []error) {
+    switch v.(string) {
+    case kubernetes_model.PropagationForeground, kubernetes_model.PropagationBackground, kubernetes_model.PropagationOrphan:
+        return nil, nil
+    }
+    return nil, []error{
+        fmt.Errorf("Invalid deletion propagation: %v; possible values are \"%s\", \"%s\" and \"%s\"", v, kubernetes_model.PropagationForeground, kubernetes_model.PropagationBackground, kubernetes_model.PropagationOrphan),
+    }
+}
+
+func validateLabelSelector(v interface{}, _ string) ([]string, []error) {
+    if _, err := kubernetes_model.ParseLabelSelector(v.(string)); err != nil {
+        return nil, []error{err}
+    }
+    return nil, nil
+}
+
+func validateProtectionAction(v interface{}, _ string) ([]string, []error) {
+    if value := v.(string); value != kubernetes_model.ProtectionRefuse && value != kubernetes_model.ProtectionAbandon {
+        return nil, []error{
+            fmt.Errorf("Invalid protection action: %v; possible values are \"%s\" and \"%s\"", v, kubernetes_model.ProtectionRefuse, kubernetes_model.ProtectionAbandon),
+        }
+    }
+    return nil, nil
+}
+
+func validateTlsVersion(v interface{}, _ string) ([]string, []error) {
+    switch v.(string) {
+    case "1.0", "1.1", "1.2":
+        return nil, nil
+    }
+    return nil, []error{
+        fmt.Errorf("Invalid TLS version: %v; possible values are \"1.0\", \"1.1\" and \"1.2\"", v),
+    }
+}
+
+func validateProxyUrl(v interface{}, _ string) ([]string, []error) {
+    if proxyUrl, err := url.Parse(v.(string)); err != nil || proxyUrl.Host == "" || (proxyUrl.Scheme != "http" && proxyUrl.Scheme != "https" && proxyUrl.Scheme != "socks5") {
+        return nil, []error{
+            fmt.Errorf("Invalid proxy URL: %v; expected \"http://\", \"https://\" or \"socks5://\" URL", v),
+        }
+    }
+    return nil, nil
+}
+
+func validateExecApiVersion(v interface{}, _ string) ([]string, []error) {
+    for _, apiVersion := range kubernetes_cluster.ExecApiVersions {
+        if v.(string) == apiVersion {
+            return nil, nil
+        }
+    }
+    return nil, []error{
+        fmt.Errorf("Invalid exec API version: %v; possible values are \"%s\"", v, strings.Join(kubernetes_cluster.ExecApiVersions, "\", \"")),
+    }
+}
+
+func validateDuration(v interface{}, _ string) ([]string, []error) {
+    if duration, err := time.ParseDuration(v.(string)); err != nil || duration <= 0 {
+        return nil, []error{
+            fmt.Errorf("Invalid duration: %v; expected positive value like \"30s\" or \"10m\"", v),
+        }
+    }
+    return nil, nil
+}
+
+// Terraform parses the "timeouts" block itself and does not tell whether it is specified,
+// so it is also kept as a regular attribute to apply the provider defaults only to the missing timeouts
+func timeoutsSchema() *schema.Schema {
+    timeoutSchema := make(map[string]*schema.Schema, len(kubernetes_cluster.TimeoutKeys))
+    for _, key := range kubernetes_cluster.TimeoutKeys {
+        timeoutSchema[key] = &schema.Schema{
+            Type:     schema.TypeString,
+            Optional: true,
+        }
+    }
+
+    return &schema.Schema{
+        Type:     schema.TypeList,
+        Optional: true,
+        MaxItems: 1,
+        Elem: &schema.Resource{
+            Schema: timeoutSchema,
+        },
+    }
+}
+
+func resourceTimeouts() *schema.ResourceTimeout {
+    return &schema.ResourceTimeout{
+        Create: schema.DefaultTimeout(defaultTimeouts[schema.TimeoutCreate]),
+        Read:   schema.DefaultTimeout(defaultTimeouts[schema.TimeoutRead]),
+        Update: schema.DefaultTimeout(defaultTimeouts[schema.TimeoutUpdate]),
+        Delete: schema.DefaultTimeout(defaultTimeouts[schema.TimeoutDelete]),
+    }
+}
+
 func configureKubernetesProvider(clusterData *schema.ResourceData) (interface{}, error) {
-    return kubernetes_cluster.New(clusterData), nil
+    cluster, err := kubernetes_cluster.New(clusterData)
+    if err != nil {
+        return nil, err
+    }
+
+    return &kubernetes_cluster.ProviderConfig{
+        Cluster:       cluster,
+        EncryptionKey: clusterData.Get("cluster_encryption_key").(string),
+    }, nil
 }
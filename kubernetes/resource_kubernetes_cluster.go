@@ -5,17 +5,23 @@ import (
     "github.com/hashicorp/terraform/helper/schema"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/client"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
+    "log"
+    "time"
 )
 
-func createKubernetesCluster(clusterData *schema.ResourceData, _ interface{}) error {
+func createKubernetesCluster(clusterData *schema.ResourceData, meta interface{}) error {
     id, err := uuid.GenerateUUID()
     if err != nil {
         return err
     }
 
-    cluster := kubernetes_cluster.New(clusterData)
+    cluster, err := kubernetes_cluster.New(clusterData)
+    if err != nil {
+        return err
+    }
 
-    client, err := kubernetes_client.New(cluster)
+    client, err := kubernetes_client.New(cluster, clusterData.Timeout(schema.TimeoutCreate))
     if err != nil {
         return err
     }
@@ -24,37 +30,137 @@ func createKubernetesCluster(clusterData *schema.ResourceData, _ interface{}) er
         return err
     }
 
-    if err := updateCluster(clusterData, cluster); err != nil {
+    if err = client.WaitForClusterHealth(kubernetes_model.ParseHealthGate(clusterData)); err != nil {
+        return err
+    }
+
+    serverInfo, err := client.ServerInfo()
+    if err != nil {
+        return err
+    }
+
+    if err := updateCluster(clusterData, cluster, meta); err != nil {
         return err
     }
 
+    setServerInfo(clusterData, serverInfo)
+
     clusterData.SetId(id)
 
     return nil
 }
 
-func readKubernetesCluster(_ *schema.ResourceData, _ interface{}) error {
+func readKubernetesCluster(clusterData *schema.ResourceData, meta interface{}) error {
+    encodedCluster := clusterData.Get("cluster").(string)
+    if encodedCluster == "" {
+        return nil
+    }
+
+    encryptionKey := meta.(*kubernetes_cluster.ProviderConfig).EncryptionKey
+
+    cluster, err := kubernetes_cluster.Decode(encodedCluster, encryptionKey)
+    if err != nil {
+        return err
+    }
+
+    if encryptionKey != "" && !kubernetes_cluster.IsEncrypted(encodedCluster) {
+        log.Printf("[WARN] Cluster %s was stored before \"cluster_encryption_key\" was set, it is encrypted on the next update", clusterData.Id())
+    }
+
+    setCertificatesExpiry(clusterData, cluster)
+
+    var serverInfo *kubernetes_client.ServerInfo
+
+    client, err := kubernetes_client.New(cluster, clusterData.Timeout(schema.TimeoutRead))
+    if err == nil {
+        serverInfo, err = client.ServerInfo()
+    }
+
+    if err != nil { // the cluster may be temporarily down or the credentials expired, that must not prevent updating them
+        log.Printf("[WARN] Kubernetes API server %s is unreachable: %v", cluster.ApiServer, err)
+        serverInfo = &kubernetes_client.ServerInfo{}
+    }
+
+    setServerInfo(clusterData, serverInfo)
+
     return nil
 }
 
-func updateKubernetesCluster(clusterData *schema.ResourceData, _ interface{}) error {
-    return updateCluster(clusterData, kubernetes_cluster.New(clusterData))
+func updateKubernetesCluster(clusterData *schema.ResourceData, meta interface{}) error {
+    cluster, err := kubernetes_cluster.New(clusterData)
+    if err != nil {
+        return err
+    }
+
+    var serverInfo *kubernetes_client.ServerInfo
+
+    client, err := kubernetes_client.New(cluster, clusterData.Timeout(schema.TimeoutUpdate))
+    if err == nil {
+        serverInfo, err = client.ServerInfo()
+    }
+
+    if err != nil { // e.g. the expired certificates are being replaced by this very update
+        log.Printf("[WARN] Kubernetes API server %s is unreachable: %v", cluster.ApiServer, err)
+        serverInfo = &kubernetes_client.ServerInfo{}
+    }
+
+    if err := updateCluster(clusterData, cluster, meta); err != nil {
+        return err
+    }
+
+    setServerInfo(clusterData, serverInfo)
+
+    return nil
 }
 
 func deleteKubernetesCluster(clusterData *schema.ResourceData, _ interface{}) error {
     clusterData.SetId("")
     clusterData.Set("cluster", "")
+    clusterData.Set("kubeconfig", "")
 
     return nil
 }
 
-func updateCluster(clusterData *schema.ResourceData, cluster *kubernetes_cluster.Cluster) error {
-    encodedCluster, err := cluster.Encode()
+func updateCluster(clusterData *schema.ResourceData, cluster *kubernetes_cluster.Cluster, meta interface{}) error {
+    encryptionKey := meta.(*kubernetes_cluster.ProviderConfig).EncryptionKey
+
+    encodedCluster, err := cluster.Reencode(clusterData.Get("cluster").(string), encryptionKey)
+    if err != nil {
+        return err
+    }
+
+    // Credentials must not leak into the state in plaintext when the cluster itself is encrypted
+    kubeConfig, err := cluster.KubeConfig(clusterData.Get("kubeconfig_context_name").(string), encryptionKey == "")
     if err != nil {
         return err
     }
 
     clusterData.Set("cluster", encodedCluster)
+    clusterData.Set("kubeconfig", kubeConfig)
+
+    setCertificatesExpiry(clusterData, cluster)
 
     return nil
 }
+
+func setCertificatesExpiry(clusterData *schema.ResourceData, cluster *kubernetes_cluster.Cluster) {
+    clientCertNotAfter, _ := cluster.ClientCertNotAfter()
+    caCertNotAfter, _ := cluster.CaCertNotAfter()
+
+    clusterData.Set("client_cert_not_after", formatTime(clientCertNotAfter))
+    clusterData.Set("ca_cert_not_after", formatTime(caCertNotAfter))
+}
+
+func formatTime(t time.Time) string {
+    if t.IsZero() {
+        return ""
+    }
+    return t.UTC().Format(time.RFC3339)
+}
+
+func setServerInfo(clusterData *schema.ResourceData, serverInfo *kubernetes_client.ServerInfo) {
+    clusterData.Set("server_version", serverInfo.Version)
+    clusterData.Set("git_version", serverInfo.GitVersion)
+    clusterData.Set("platform", serverInfo.Platform)
+    clusterData.Set("api_versions", serverInfo.ApiVersions)
+}
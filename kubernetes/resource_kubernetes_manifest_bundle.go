@@ -0,0 +1,215 @@
+package kubernetes
+
+import (
+    "github.com/hashicorp/go-uuid"
+    "github.com/hashicorp/terraform/helper/schema"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/client"
+    "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
+    "log"
+)
+
+func createKubernetesManifestBundle(resourceData *schema.ResourceData, meta interface{}) error {
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutCreate)
+    if err != nil {
+        return err
+    }
+
+    id, err := uuid.GenerateUUID()
+    if err != nil {
+        return err
+    }
+
+    kubeResources, err := kubernetes_model.ParseResources(resourceData)
+    if err != nil {
+        return err
+    }
+
+    if err := kubeClient.Resolve(kubeResources...); err != nil {
+        return err
+    }
+
+    resourceData.SetId(id) // resources created so far must be tracked in state even if some of them fail
+
+    paths := make([]string, 0, len(kubeResources))
+
+    for _, kubeResource := range kubeResources {
+        if err := kubeClient.Create(kubeResource); err != nil {
+            return err
+        }
+
+        paths = append(paths, kubeResource.Path())
+        resourceData.Set("paths", paths)
+    }
+
+    return setDestroyOutcomes(kubeClient, resourceData, paths)
+}
+
+func readKubernetesManifestBundle(resourceData *schema.ResourceData, meta interface{}) error {
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutRead)
+    if err != nil {
+        return err
+    }
+
+    paths := getBundlePaths(resourceData)
+    existingPaths := make([]string, 0, len(paths))
+
+    for _, path := range paths {
+        exists, err := kubeClient.Exists(kubernetes_model.ParsePath(path))
+        if err != nil {
+            return err
+        }
+
+        if exists {
+            existingPaths = append(existingPaths, path)
+        }
+    }
+
+    if len(existingPaths) == 0 {
+        resourceData.SetId("")
+        return nil
+    }
+
+    if len(existingPaths) < len(paths) {
+        log.Printf("[WARN] %d of %d objects of the manifest bundle %s were deleted outside of Terraform", len(paths) - len(existingPaths), len(paths), resourceData.Id())
+        resourceData.Set("contents", "") // forces an update which re-creates the missing objects
+    }
+
+    resourceData.Set("paths", existingPaths)
+
+    return setDestroyOutcomes(kubeClient, resourceData, existingPaths)
+}
+
+func updateKubernetesManifestBundle(resourceData *schema.ResourceData, meta interface{}) error {
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutUpdate)
+    if err != nil {
+        return err
+    }
+
+    kubeResources, err := kubernetes_model.ParseResources(resourceData)
+    if err != nil {
+        return err
+    }
+
+    if err := kubeClient.Resolve(kubeResources...); err != nil {
+        return err
+    }
+
+    oldPaths := getBundlePaths(resourceData)
+
+    oldPathSet := make(map[string]bool, len(oldPaths))
+    for _, path := range oldPaths {
+        oldPathSet[path] = true
+    }
+
+    newPaths := make([]string, 0, len(kubeResources))
+    newPathSet := make(map[string]bool, len(kubeResources))
+
+    // Until the update completes, state keeps both the new objects and the old ones which are not deleted yet
+    trackedPaths := func(stalePaths []string) []string {
+        paths := append(make([]string, 0, len(newPaths) + len(stalePaths)), newPaths...)
+        for _, path := range stalePaths {
+            if !newPathSet[path] {
+                paths = append(paths, path)
+            }
+        }
+        return paths
+    }
+
+    for _, kubeResource := range kubeResources {
+        path := kubeResource.Path()
+
+        if oldPathSet[path] {
+            err = kubeClient.Update(kubeResource)
+        } else {
+            err = kubeClient.Create(kubeResource)
+        }
+
+        if err != nil {
+            return err
+        }
+
+        newPaths = append(newPaths, path)
+        newPathSet[path] = true
+
+        resourceData.Set("paths", trackedPaths(oldPaths))
+    }
+
+    for i := len(oldPaths) - 1; i >= 0; i-- {
+        if path := oldPaths[i]; !newPathSet[path] {
+            if err := kubeClient.Delete(kubernetes_model.ParsePath(path), kubernetes_model.ParseDeleteOptions(resourceData)); err != nil {
+                return err
+            }
+        }
+
+        resourceData.Set("paths", trackedPaths(oldPaths[:i]))
+    }
+
+    return setDestroyOutcomes(kubeClient, resourceData, newPaths)
+}
+
+func deleteKubernetesManifestBundle(resourceData *schema.ResourceData, meta interface{}) error {
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutDelete)
+    if err != nil {
+        return err
+    }
+
+    paths := getBundlePaths(resourceData)
+
+    for i := len(paths) - 1; i >= 0; i-- { // delete in reverse order so that namespaces go after their contents
+        if err := kubeClient.Delete(kubernetes_model.ParsePath(paths[i]), kubernetes_model.ParseDeleteOptions(resourceData)); err != nil {
+            return err
+        }
+
+        resourceData.Set("paths", paths[:i])
+    }
+
+    resourceData.SetId("")
+
+    return nil
+}
+
+func kubernetesManifestBundleExists(resourceData *schema.ResourceData, meta interface{}) (bool, error) {
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutRead)
+    if err != nil {
+        return false, err
+    }
+
+    for _, path := range getBundlePaths(resourceData) {
+        exists, err := kubeClient.Exists(kubernetes_model.ParsePath(path))
+        if err != nil || exists {
+            return exists, err
+        }
+    }
+
+    return false, nil
+}
+
+func setDestroyOutcomes(kubeClient *kubernetes_client.KubeClient, resourceData *schema.ResourceData, paths []string) error {
+    options := kubernetes_model.ParseDeleteOptions(resourceData)
+    outcomes := make(map[string]interface{}, len(paths))
+
+    for _, path := range paths {
+        outcome, err := kubeClient.DestroyOutcome(kubernetes_model.ParsePath(path), options, nil)
+        if err != nil {
+            return err
+        }
+        outcomes[path] = outcome.String()
+    }
+
+    resourceData.Set("destroy_outcomes", outcomes)
+
+    return nil
+}
+
+func getBundlePaths(resourceData *schema.ResourceData) []string {
+    rawPaths := resourceData.Get("paths").([]interface{})
+
+    paths := make([]string, 0, len(rawPaths))
+    for _, rawPath := range rawPaths {
+        if path, ok := rawPath.(string); ok && path != "" {
+            paths = append(paths, path)
+        }
+    }
+
+    return paths
+}
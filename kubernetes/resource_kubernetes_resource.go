@@ -1,26 +1,27 @@
 package kubernetes
 
 import (
-    "github.com/hashicorp/go-uuid"
+    "fmt"
     "github.com/hashicorp/terraform/helper/schema"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/client"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/cluster"
     "github.com/maxmanuylov/terraform-provider-kubernetes/kubernetes/model"
+    "log"
+    "strings"
 )
 
 func createKubernetesResource(resourceData *schema.ResourceData, meta interface{}) error {
-    kubeClient, err := loadClient(resourceData, meta)
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutCreate)
     if err != nil {
         return err
     }
 
-    id, err := uuid.GenerateUUID()
+    kubeResource, err := kubernetes_model.ParseResource(resourceData)
     if err != nil {
         return err
     }
 
-    kubeResource, err := kubernetes_model.ParseResource(resourceData)
-    if err != nil {
+    if err := kubeClient.Resolve(kubeResource); err != nil {
         return err
     }
 
@@ -29,26 +30,55 @@ func createKubernetesResource(resourceData *schema.ResourceData, meta interface{
     }
 
     resourceData.Set("path", kubeResource.Path())
-    resourceData.SetId(id)
+    resourceData.Set("drifted_fields", []string{})
+    resourceData.SetId(kubeResource.Path()) // the same ID as on import
 
-    return nil
+    return setDestroyOutcome(kubeClient, resourceData, nil)
 }
 
 func readKubernetesResource(resourceData *schema.ResourceData, meta interface{}) error {
-    exists, err := kubernetesResourceExists(resourceData, meta)
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutRead)
     if err != nil {
         return err
     }
 
-    if !exists {
+    path := resourceData.Get("path").(string)
+    if path == "" {
         resourceData.SetId("")
+        return nil
     }
 
-    return nil
+    liveContents, err := kubeClient.Get(kubernetes_model.ParsePath(path))
+    if err == kubernetes_client.ErrNotFound {
+        resourceData.SetId("")
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+
+    kubeResource, err := kubernetes_model.ParseResource(resourceData)
+    if err != nil {
+        return err
+    }
+
+    driftedFields, contents, err := kubernetes_model.Drift(kubeResource, liveContents)
+    if err != nil {
+        return err
+    }
+
+    if len(driftedFields) != 0 {
+        log.Printf("[WARN] %s differs from its configuration in: %s", path, strings.Join(driftedFields, ", "))
+        resourceData.Set("contents", string(contents)) // makes the next plan show an update
+    }
+
+    resourceData.Set("drifted_fields", driftedFields)
+
+    return setDestroyOutcome(kubeClient, resourceData, liveContents)
 }
 
 func updateKubernetesResource(resourceData *schema.ResourceData, meta interface{}) error {
-    kubeClient, err := loadClient(resourceData, meta)
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutUpdate)
     if err != nil {
         return err
     }
@@ -58,14 +88,19 @@ func updateKubernetesResource(resourceData *schema.ResourceData, meta interface{
         return err
     }
 
+    if err := kubeClient.Resolve(kubeResource); err != nil {
+        return err
+    }
+
     if path := resourceData.Get("path").(string); path == "" {
         if err := kubeClient.Create(kubeResource); err != nil {
             return err
         }
 
         resourceData.Set("path", kubeResource.Path())
+        resourceData.SetId(kubeResource.Path())
     } else if newPath := kubeResource.Path(); newPath != path {
-        if err := kubeClient.Delete(kubernetes_model.ParsePath(path)); err != nil {
+        if err := kubeClient.Delete(kubernetes_model.ParsePath(path), kubernetes_model.ParseDeleteOptions(resourceData)); err != nil {
             return err
         }
 
@@ -74,21 +109,24 @@ func updateKubernetesResource(resourceData *schema.ResourceData, meta interface{
         }
 
         resourceData.Set("path", newPath)
-    } else {
-        return kubeClient.Update(kubeResource)
+        resourceData.SetId(newPath)
+    } else if err := kubeClient.Update(kubeResource); err != nil {
+        return err
     }
 
-    return nil
+    resourceData.Set("drifted_fields", []string{})
+
+    return setDestroyOutcome(kubeClient, resourceData, nil)
 }
 
 func deleteKubernetesResource(resourceData *schema.ResourceData, meta interface{}) error {
-    kubeClient, err := loadClient(resourceData, meta)
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutDelete)
     if err != nil {
         return err
     }
 
     if path := resourceData.Get("path").(string); path != "" {
-        if err := kubeClient.Delete(kubernetes_model.ParsePath(path)); err != nil {
+        if err := kubeClient.Delete(kubernetes_model.ParsePath(path), kubernetes_model.ParseDeleteOptions(resourceData)); err != nil {
             return err
         }
     }
@@ -99,7 +137,7 @@ func deleteKubernetesResource(resourceData *schema.ResourceData, meta interface{
 }
 
 func kubernetesResourceExists(resourceData *schema.ResourceData, meta interface{}) (bool, error) {
-    kubeClient, err := loadClient(resourceData, meta)
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutRead)
     if err != nil {
         return false, err
     }
@@ -111,11 +149,81 @@ func kubernetesResourceExists(resourceData *schema.ResourceData, meta interface{
     return false, nil
 }
 
-func loadClient(resourceData *schema.ResourceData, meta interface{}) (*kubernetes_client.KubeClient, error) {
+func importKubernetesResource(resourceData *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+    kubeClient, err := loadClient(resourceData, meta, schema.TimeoutRead)
+    if err != nil {
+        return nil, err
+    }
+
+    resourcePath, err := kubernetes_model.ParseImportId(resourceData.Id())
+    if err != nil {
+        return nil, err
+    }
+
+    if resourcePath == nil {
+        namespace, kind, name, err := kubernetes_model.SplitImportId(resourceData.Id())
+        if err != nil {
+            return nil, err
+        }
+
+        if resourcePath, err = kubeClient.FindResource(namespace, kind, name); err != nil {
+            return nil, err
+        }
+    }
+
+    liveContents, err := kubeClient.Get(resourcePath)
+    if err == kubernetes_client.ErrNotFound {
+        return nil, fmt.Errorf("Resource %s does not exist", resourcePath.Path())
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    contents, err := kubernetes_model.ImportContents(liveContents, kubernetes_model.EncodingYaml)
+    if err != nil {
+        return nil, err
+    }
+
+    for key, value := range resourceSchema(false) {
+        if value.Default != nil {
+            resourceData.Set(key, value.Default)
+        }
+    }
+
+    resourceData.Set("contents", string(contents))
+    resourceData.Set("encoding", kubernetes_model.EncodingYaml)
+    resourceData.Set("global", false) // scope is resolved via API discovery
+    resourceData.Set("path", resourcePath.Path())
+    resourceData.SetId(resourcePath.Path())
+
+    return []*schema.ResourceData{resourceData}, nil
+}
+
+func setDestroyOutcome(kubeClient *kubernetes_client.KubeClient, resourceData *schema.ResourceData, liveContents []byte) error {
+    resourcePath := kubernetes_model.ParsePath(resourceData.Get("path").(string))
+
+    outcome, err := kubeClient.DestroyOutcome(resourcePath, kubernetes_model.ParseDeleteOptions(resourceData), liveContents)
+    if err != nil {
+        return err
+    }
+
+    resourceData.Set("destroy_outcome", outcome.String())
+
+    return nil
+}
+
+func loadClient(resourceData *schema.ResourceData, meta interface{}, timeoutKey string) (*kubernetes_client.KubeClient, error) {
     cluster, err := kubernetes_cluster.Load(resourceData, meta)
     if err != nil {
         return nil, err
     }
-    
-    return kubernetes_client.New(cluster)
+
+    timeout := resourceData.Timeout(timeoutKey)
+    if _, specified := resourceData.GetOk(fmt.Sprintf("timeouts.0.%s", timeoutKey)); !specified {
+        if defaultTimeout, ok := cluster.DefaultTimeout(timeoutKey); ok {
+            timeout = defaultTimeout
+        }
+    }
+
+    return kubernetes_client.New(cluster, timeout)
 }